@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff decides how long RetriableConnector waits before each retry.
+// attempt is the number of attempts made so far, starting at 1, and previous
+// is the delay returned for the previous retry, or 0 before the first.
+type Backoff interface {
+	NextDelay(attempt int, previous time.Duration) time.Duration
+}
+
+type ConstantBackoff struct {
+	Interval time.Duration
+}
+
+func (b ConstantBackoff) NextDelay(int, time.Duration) time.Duration {
+	return b.Interval
+}
+
+// ExponentialBackoff waits Initial before the first retry and multiplies the
+// delay by Multiplier (2 if unset) for each retry after that, up to Max.
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+func (b ExponentialBackoff) NextDelay(attempt int, _ time.Duration) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier == 0 {
+		multiplier = 2
+	}
+
+	delay := float64(b.Initial)
+	for i := 1; i < attempt; i++ {
+		delay *= multiplier
+		if b.Max > 0 && delay >= float64(b.Max) {
+			return b.Max
+		}
+	}
+	return time.Duration(delay)
+}
+
+const DefaultJitterBackoffBase = 100 * time.Millisecond
+
+// DecorrelatedJitterBackoff picks each delay at random between Base and three
+// times the previous delay, capped at Max. Spreading retries out this way
+// stops a fleet of clients that lost the database at the same time from
+// reconnecting in lockstep. A Base that is not positive is replaced with
+// DefaultJitterBackoffBase, as the delay could otherwise never grow from 0.
+type DecorrelatedJitterBackoff struct {
+	Base time.Duration
+	Max  time.Duration
+
+	// Int64N returns a random number in [0, n). Defaults to rand.Int64N.
+	Int64N func(n int64) int64
+}
+
+func (b DecorrelatedJitterBackoff) NextDelay(_ int, previous time.Duration) time.Duration {
+	base := b.Base
+	if base <= 0 {
+		base = DefaultJitterBackoffBase
+	}
+
+	upper := previous * 3
+	if upper < base {
+		upper = base
+	}
+	delay := base
+	if upper > base {
+		delay += time.Duration(randInt64N(b.Int64N)(int64(upper - base)))
+	}
+	if b.Max > 0 && delay > b.Max {
+		delay = b.Max
+	}
+	return delay
+}
+
+// randInt64N returns int64N, or rand.Int64N if it is nil.
+func randInt64N(int64N func(n int64) int64) func(n int64) int64 {
+	if int64N == nil {
+		// #nosec G404 - jitter does not need a cryptographically secure source
+		return rand.Int64N
+	}
+	return int64N
+}
+
+// TimerSleeper sleeps using a timer, and stops early when the context passed
+// to SleepContext is done.
+type TimerSleeper struct{}
+
+func (TimerSleeper) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+func (TimerSleeper) SleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+package db_test
+
+import (
+	"context"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Backoff", func() {
+	Describe("ConstantBackoff", func() {
+		It("always returns the interval", func() {
+			backoff := db.ConstantBackoff{Interval: time.Second}
+			Expect(backoff.NextDelay(1, 0)).To(Equal(time.Second))
+			Expect(backoff.NextDelay(5, time.Second)).To(Equal(time.Second))
+		})
+	})
+
+	Describe("ExponentialBackoff", func() {
+		It("doubles the delay for each attempt up to the max", func() {
+			backoff := db.ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second}
+			Expect(backoff.NextDelay(1, 0)).To(Equal(100 * time.Millisecond))
+			Expect(backoff.NextDelay(2, 0)).To(Equal(200 * time.Millisecond))
+			Expect(backoff.NextDelay(4, 0)).To(Equal(800 * time.Millisecond))
+			Expect(backoff.NextDelay(5, 0)).To(Equal(time.Second))
+			Expect(backoff.NextDelay(50, 0)).To(Equal(time.Second))
+		})
+
+		It("uses the multiplier when set", func() {
+			backoff := db.ExponentialBackoff{Initial: 100 * time.Millisecond, Multiplier: 3}
+			Expect(backoff.NextDelay(3, 0)).To(Equal(900 * time.Millisecond))
+		})
+	})
+
+	Describe("DecorrelatedJitterBackoff", func() {
+		var requested []int64
+
+		It("picks a delay between the base and three times the previous delay", func() {
+			backoff := db.DecorrelatedJitterBackoff{
+				Base: 100 * time.Millisecond,
+				Max:  10 * time.Second,
+				Int64N: func(n int64) int64 {
+					requested = append(requested, n)
+					return n - 1
+				},
+			}
+
+			Expect(backoff.NextDelay(1, 0)).To(Equal(100 * time.Millisecond))
+			Expect(backoff.NextDelay(2, 100*time.Millisecond)).To(Equal(300*time.Millisecond - 1))
+			Expect(requested).To(Equal([]int64{int64(200 * time.Millisecond)}))
+		})
+
+		It("defaults the base so that the delay can grow", func() {
+			backoff := db.DecorrelatedJitterBackoff{Int64N: func(n int64) int64 { return n - 1 }}
+
+			delay := backoff.NextDelay(1, 0)
+			Expect(delay).To(Equal(db.DefaultJitterBackoffBase))
+			Expect(backoff.NextDelay(2, delay)).To(Equal(3*db.DefaultJitterBackoffBase - 1))
+		})
+
+		It("caps the delay at the max", func() {
+			backoff := db.DecorrelatedJitterBackoff{Base: 100 * time.Millisecond, Max: time.Second}
+			for i := 0; i < 100; i++ {
+				delay := backoff.NextDelay(2, 5*time.Second)
+				Expect(delay).To(BeNumerically(">=", 100*time.Millisecond))
+				Expect(delay).To(BeNumerically("<=", time.Second))
+			}
+		})
+	})
+
+	Describe("TimerSleeper", func() {
+		It("sleeps for the duration", func() {
+			start := time.Now()
+			Expect(db.TimerSleeper{}.SleepContext(context.Background(), 20*time.Millisecond)).To(Succeed())
+			Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+		})
+
+		It("returns early when the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			start := time.Now()
+			Expect(db.TimerSleeper{}.SleepContext(ctx, time.Minute)).To(MatchError(context.Canceled))
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+		})
+	})
+})
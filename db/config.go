@@ -3,6 +3,7 @@ package db
 import (
 	"fmt"
 	"net/url"
+	"strings"
 	"time"
 )
 
@@ -17,39 +18,119 @@ type Config struct {
 	RequireSSL             bool   `json:"require_ssl" validate:""`
 	CACert                 string `json:"ca_cert" validate:""`
 	SkipHostnameValidation bool   `json:"skip_hostname_validation" validate:""`
+	ClientCert             string `json:"client_cert" validate:""`
+	ClientKey              string `json:"client_key" validate:""`
+
+	FailoverHosts []string `json:"failover_hosts" validate:""`
+	ReplicaHosts  []string `json:"replica_hosts" validate:""`
+
+	// Connection pool settings. Zero leaves the setting at its default; see
+	// PoolConfig.
+	MaxOpenConnections int `json:"max_open_connections" validate:"min=0"`
+	MaxIdleConnections int `json:"max_idle_connections" validate:"min=0"`
+	ConnMaxLifetime    int `json:"conn_max_lifetime_seconds" validate:"min=0"`
+	ConnMaxIdleTime    int `json:"conn_max_idle_time_seconds" validate:"min=0"`
+}
+
+// AllHosts returns Host followed by FailoverHosts, in the order connections
+// are attempted.
+func (c Config) AllHosts() []string {
+	return append([]string{c.Host}, c.FailoverHosts...)
+}
+
+// RotateHosts returns a copy of c with Host moved to the end of
+// FailoverHosts and the first failover host promoted to Host.
+func (c Config) RotateHosts() Config {
+	if len(c.FailoverHosts) == 0 {
+		return c
+	}
+	hosts := c.AllHosts()
+	c.Host = hosts[1]
+	c.FailoverHosts = append(hosts[2:], hosts[0])
+	return c
+}
+
+// ReplicaConfigs returns a Config for each of ReplicaHosts. Replicas share
+// every setting other than the host with the primary.
+func (c Config) ReplicaConfigs() []Config {
+	configs := make([]Config, 0, len(c.ReplicaHosts))
+	for _, host := range c.ReplicaHosts {
+		replica := c
+		replica.Host = host
+		replica.FailoverHosts = nil
+		replica.ReplicaHosts = nil
+		configs = append(configs, replica)
+	}
+	return configs
 }
 
 func (c Config) ConnectionString() (string, error) {
+	connectionString, _, err := c.connectionString()
+	return connectionString, err
+}
+
+// connectionString also returns a function that releases any driver state
+// registered for the connection string, such as a MySQL TLS config, to be
+// called once the pool using it is closed.
+func (c Config) connectionString() (string, func(), error) {
 	if c.Timeout < 1 {
-		return "", fmt.Errorf("timeout must be at least 1 second: %d", c.Timeout)
+		return "", nil, fmt.Errorf("timeout must be at least 1 second: %d", c.Timeout)
 	}
 	switch c.Type {
 	case "postgres":
-		return buildPostgresConnectionString(c)
+		connectionString, err := buildPostgresConnectionString(c)
+		return connectionString, func() {}, err
 	case "mysql":
 		mysqlConnectionStringBuilder := &MySQLConnectionStringBuilder{
 			MySQLAdapter: &MySQLAdapter{},
 		}
-		return mysqlConnectionStringBuilder.Build(c)
+		return mysqlConnectionStringBuilder.BuildWithRelease(c)
+	case "sqlite":
+		connectionString, err := buildSQLiteConnectionString(c)
+		return connectionString, func() {}, err
 	default:
-		return "", fmt.Errorf("database type '%s' is not supported", c.Type)
+		return "", nil, fmt.Errorf("database type '%s' is not supported", c.Type)
+	}
+}
+
+func (c Config) validateClientCert() error {
+	if c.ClientCert != "" && c.ClientKey == "" {
+		return fmt.Errorf("`ClientCert` is provided but `ClientKey` is not")
 	}
+	if c.ClientKey != "" && c.ClientCert == "" {
+		return fmt.Errorf("`ClientKey` is provided but `ClientCert` is not")
+	}
+	// the client certificate is only presented over TLS, so it would be ignored
+	if c.ClientCert != "" && !c.RequireSSL {
+		return fmt.Errorf("`ClientCert` is provided but `RequireSSL` is not set")
+	}
+	return nil
 }
 
 func buildPostgresConnectionString(c Config) (string, error) {
+	if err := c.validateClientCert(); err != nil {
+		return "", err
+	}
+
 	ms := (time.Duration(c.Timeout) * time.Second).Nanoseconds() / 1000 / 1000
 	sslmode := "disable"
 	params := url.Values{}
 
 	if c.RequireSSL {
+		if c.CACert == "" {
+			return "", fmt.Errorf("SSL is required but `CACert` is not provided")
+		}
+		sslmode = "verify-full"
 		if c.SkipHostnameValidation {
-			sslmode = "require"
-		} else {
-			if c.CACert == "" {
-				return "", fmt.Errorf("SSL is required but `CACert` is not provided")
-			}
-			sslmode = "verify-full"
-			params.Add("sslrootcert", c.CACert)
+			// verify-ca checks the server certificate chain against CACert but
+			// not the hostname, like VerifyCertificatesIgnoreHostname for MySQL.
+			sslmode = "verify-ca"
+		}
+		params.Add("sslrootcert", c.CACert)
+
+		if c.ClientCert != "" {
+			params.Add("sslcert", c.ClientCert)
+			params.Add("sslkey", c.ClientKey)
 		}
 	}
 
@@ -65,3 +146,30 @@ func buildPostgresConnectionString(c Config) (string, error) {
 	}
 	return connURL.String(), nil
 }
+
+// buildSQLiteConnectionString opens the database file at DatabaseName, or a
+// URI such as "file:test?mode=memory&cache=shared" for an in-memory database
+// shared by every connection in the pool. The connection settings other than
+// Timeout do not apply to SQLite and are ignored. The driver is only
+// registered by importing db/sqlite.
+func buildSQLiteConnectionString(c Config) (string, error) {
+	if c.DatabaseName == "" {
+		return "", fmt.Errorf("sqlite requires `DatabaseName` to be the path of the database file")
+	}
+
+	ms := (time.Duration(c.Timeout) * time.Second).Milliseconds()
+	params := url.Values{}
+	params.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", ms))
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_txlock", "immediate")
+
+	name := c.DatabaseName
+	if !strings.HasPrefix(name, "file:") {
+		name = "file:" + name
+	}
+	separator := "?"
+	if strings.Contains(name, "?") {
+		separator = "&"
+	}
+	return name + separator + params.Encode(), nil
+}
@@ -1,53 +1,241 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"time"
 
 	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/lager/v3"
 	"github.com/jmoiron/sqlx"
 )
 
 type ConnWrapper struct {
 	*sqlx.DB
 	Monitor monitor.Monitor
+
+	// Statements that take longer than SlowQueryThreshold are logged to
+	// Logger and counted by Monitor. A zero threshold disables this.
+	Logger             lager.Logger
+	SlowQueryThreshold time.Duration
+
+	// StatementTimeout bounds each statement run with a context that has no
+	// deadline, including those run by the methods without a context and in
+	// transactions. For statements returning rows it also bounds reading
+	// them. Statements that time out are counted by Monitor as timed out
+	// rather than failed. A zero timeout disables this.
+	StatementTimeout time.Duration
+
+	queryName string
+	release   func()
+}
+
+// Close closes the pool and releases the driver state registered for it, such
+// as a MySQL TLS config.
+func (c *ConnWrapper) Close() error {
+	err := c.DB.Close()
+	if c.release != nil {
+		c.release()
+	}
+	return err
+}
+
+// Named returns a ConnWrapper sharing the same pool and monitor whose queries
+// are tracked under name. A name set on the context with
+// monitor.WithQueryName takes precedence.
+func (c *ConnWrapper) Named(name string) *ConnWrapper {
+	named := *c
+	named.queryName = name
+	return &named
+}
+
+func (c *ConnWrapper) queryMonitor(ctx context.Context) queryMonitor {
+	name := monitor.QueryName(ctx)
+	if name == "" {
+		name = c.queryName
+	}
+	return queryMonitor{
+		monitor:            c.Monitor,
+		name:               name,
+		logger:             c.Logger,
+		slowQueryThreshold: c.SlowQueryThreshold,
+		statementTimeout:   c.StatementTimeout,
+	}
 }
 
 func (c *ConnWrapper) Beginx() (Transaction, error) {
+	return c.BeginTxx(context.Background(), nil)
+}
+
+func (c *ConnWrapper) BeginTxx(ctx context.Context, opts *sql.TxOptions) (Transaction, error) {
 	var innerTx *sqlx.Tx
-	err := c.Monitor.Monitor(func() error {
+	queryMonitor := c.queryMonitor(ctx)
+	// ctx bounds the whole transaction, so the statement timeout is applied
+	// to the statements run in it rather than here
+	err := queryMonitor.run("BEGIN", func() error {
 		var err error
-		innerTx, err = c.DB.Beginx()
+		innerTx, err = c.DB.BeginTxx(ctx, opts)
 		return err
 	})
 
 	tx := &monitoredTx{
 		tx:      innerTx,
-		monitor: c.Monitor,
+		monitor: queryMonitor,
 	}
 
 	return tx, err
 }
 
+// Begin starts a transaction whose statements, unlike those of Beginx, are
+// not monitored.
+func (c *ConnWrapper) Begin() (*sql.Tx, error) {
+	return c.BeginTx(context.Background(), nil)
+}
+
+func (c *ConnWrapper) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	var tx *sql.Tx
+	err := c.queryMonitor(ctx).run("BEGIN", func() error {
+		var err error
+		tx, err = c.DB.BeginTx(ctx, opts)
+		return err
+	})
+	return tx, err
+}
+
+func (c *ConnWrapper) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.ExecContext(context.Background(), query, args...)
+}
+
+func (c *ConnWrapper) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := c.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = c.DB.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+// MustExec runs query like Exec and panics if it fails.
+func (c *ConnWrapper) MustExec(query string, args ...interface{}) sql.Result {
+	return c.MustExecContext(context.Background(), query, args...)
+}
+
+func (c *ConnWrapper) MustExecContext(ctx context.Context, query string, args ...interface{}) sql.Result {
+	result, err := c.ExecContext(ctx, query, args...)
+	if err != nil {
+		panic(err)
+	}
+	return result
+}
+
 func (c *ConnWrapper) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), query, args...)
+}
+
+func (c *ConnWrapper) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
 	var result *sql.Rows
-	err := c.Monitor.Monitor(func() error {
+	err := c.queryMonitor(ctx).runRows(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = c.DB.QueryContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (c *ConnWrapper) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return c.QueryxContext(context.Background(), query, args...)
+}
+
+func (c *ConnWrapper) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	var result *sqlx.Rows
+	err := c.queryMonitor(ctx).runRows(ctx, query, func(ctx context.Context) error {
 		var err error
-		result, err = c.DB.Query(query, args...)
+		result, err = c.DB.QueryxContext(ctx, query, args...)
 		return err
 	})
 	return result, err
 }
 
 func (c *ConnWrapper) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext runs query and returns its row. The statement timeout, if
+// any, also bounds scanning the row, and is reported by Scan as the driver's
+// context error rather than a timeouts error.
+func (c *ConnWrapper) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	var result *sql.Row
-	// #nosec G104 - the Monitor function  only returns an error if the passed function errors, which this doesn't. we just want to log queries in our counters here
-	c.Monitor.Monitor(func() error {
-		result = c.DB.QueryRow(query, args...)
-		return nil
+	// #nosec G104 - the error is carried by the returned row
+	c.queryMonitor(ctx).runRows(ctx, query, func(ctx context.Context) error {
+		result = c.DB.QueryRowContext(ctx, query, args...)
+		return result.Err()
+	})
+	return result
+}
+
+func (c *ConnWrapper) QueryRowx(query string, args ...interface{}) *sqlx.Row {
+	return c.QueryRowxContext(context.Background(), query, args...)
+}
+
+func (c *ConnWrapper) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	var result *sqlx.Row
+	// #nosec G104 - the error is carried by the returned row
+	c.queryMonitor(ctx).runRows(ctx, query, func(ctx context.Context) error {
+		result = c.DB.QueryRowxContext(ctx, query, args...)
+		return result.Err()
 	})
 	return result
 }
 
+func (c *ConnWrapper) Get(dest interface{}, query string, args ...interface{}) error {
+	return c.GetContext(context.Background(), dest, query, args...)
+}
+
+func (c *ConnWrapper) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return c.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		return c.DB.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (c *ConnWrapper) Select(dest interface{}, query string, args ...interface{}) error {
+	return c.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (c *ConnWrapper) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return c.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		return c.DB.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+func (c *ConnWrapper) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return c.NamedExecContext(context.Background(), query, arg)
+}
+
+func (c *ConnWrapper) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := c.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = c.DB.NamedExecContext(ctx, query, arg)
+		return err
+	})
+	return result, err
+}
+
+func (c *ConnWrapper) NamedQuery(query string, arg interface{}) (*sqlx.Rows, error) {
+	return c.NamedQueryContext(context.Background(), query, arg)
+}
+
+func (c *ConnWrapper) NamedQueryContext(ctx context.Context, query string, arg interface{}) (*sqlx.Rows, error) {
+	var result *sqlx.Rows
+	err := c.queryMonitor(ctx).runRows(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = c.DB.NamedQueryContext(ctx, query, arg)
+		return err
+	})
+	return result, err
+}
+
 func (c *ConnWrapper) OpenConnections() int {
 	return c.DB.Stats().OpenConnections
 }
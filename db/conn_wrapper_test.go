@@ -0,0 +1,269 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor/monitorfakes"
+	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("ConnWrapper", func() {
+	var (
+		dbConf      db.Config
+		database    *db.ConnWrapper
+		fakeMonitor *monitorfakes.FakeMonitor
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		dbConf = testsupport.GetDBConfig()
+		dbConf.DatabaseName = fmt.Sprintf("test_%x", randomGenerator.Int())
+		testsupport.CreateDatabase(dbConf)
+
+		var err error
+		database, err = db.GetConnectionPool(dbConf, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeMonitor = &monitorfakes.FakeMonitor{}
+		fakeMonitor.MonitorNamedStub = func(_ string, f func() error) error {
+			return f()
+		}
+		database.Monitor = fakeMonitor
+		ctx = context.Background()
+
+		_, err = database.RawConnection().Exec("CREATE TABLE widgets (id INT PRIMARY KEY, name VARCHAR(255));")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = database.RawConnection().Exec(database.Rebind("INSERT INTO widgets (id, name) VALUES (?, ?), (?, ?);"), 1, "one", 2, "two")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		database.Close()
+		testsupport.RemoveDatabase(dbConf)
+	})
+
+	type widget struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	It("monitors ExecContext", func() {
+		_, err := database.ExecContext(ctx, database.Rebind("DELETE FROM widgets WHERE id = ?"), 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors QueryContext", func() {
+		rows, err := database.QueryContext(ctx, "SELECT id FROM widgets")
+		Expect(err).NotTo(HaveOccurred())
+		defer rows.Close()
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors QueryxContext", func() {
+		rows, err := database.QueryxContext(ctx, "SELECT id FROM widgets")
+		Expect(err).NotTo(HaveOccurred())
+		defer rows.Close()
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors QueryRowContext", func() {
+		var name string
+		err := database.QueryRowContext(ctx, database.Rebind("SELECT name FROM widgets WHERE id = ?"), 2).Scan(&name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("two"))
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors QueryRowxContext", func() {
+		var w widget
+		err := database.QueryRowxContext(ctx, database.Rebind("SELECT id, name FROM widgets WHERE id = ?"), 2).StructScan(&w)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(Equal(widget{ID: 2, Name: "two"}))
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors QueryRowx", func() {
+		var name string
+		err := database.QueryRowx(database.Rebind("SELECT name FROM widgets WHERE id = ?"), 1).Scan(&name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(name).To(Equal("one"))
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors GetContext", func() {
+		var w widget
+		err := database.GetContext(ctx, &w, database.Rebind("SELECT id, name FROM widgets WHERE id = ?"), 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(w).To(Equal(widget{ID: 1, Name: "one"}))
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors SelectContext", func() {
+		var ws []widget
+		err := database.SelectContext(ctx, &ws, "SELECT id, name FROM widgets ORDER BY id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ws).To(Equal([]widget{{ID: 1, Name: "one"}, {ID: 2, Name: "two"}}))
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors NamedExecContext", func() {
+		_, err := database.NamedExecContext(ctx, "INSERT INTO widgets (id, name) VALUES (:id, :name)", widget{ID: 3, Name: "three"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors NamedQueryContext", func() {
+		rows, err := database.NamedQueryContext(ctx, "SELECT id FROM widgets WHERE name = :name", widget{Name: "one"})
+		Expect(err).NotTo(HaveOccurred())
+		defer rows.Close()
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors BeginTxx and passes the transaction options through", func() {
+		tx, err := database.BeginTxx(ctx, &sql.TxOptions{ReadOnly: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Rollback()).To(Succeed())
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(2))
+	})
+
+	It("monitors BeginTx and passes the transaction options through", func() {
+		tx, err := database.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Rollback()).To(Succeed())
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors Begin", func() {
+		tx, err := database.Begin()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Rollback()).To(Succeed())
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("monitors MustExec", func() {
+		database.MustExec(database.Rebind("DELETE FROM widgets WHERE id = ?"), 1)
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	It("panics from MustExec when the statement fails", func() {
+		Expect(func() { database.MustExec("DELETE FROM gadgets") }).To(Panic())
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+	})
+
+	Describe("query names", func() {
+		It("tracks queries made through Named under that name", func() {
+			_, err := database.Named("delete-widget").ExecContext(ctx, "DELETE FROM widgets")
+			Expect(err).NotTo(HaveOccurred())
+
+			name, _ := fakeMonitor.MonitorNamedArgsForCall(0)
+			Expect(name).To(Equal("delete-widget"))
+		})
+
+		It("prefers the name set on the context", func() {
+			namedCtx := monitor.WithQueryName(ctx, "select-widgets")
+			var ids []int
+			err := database.Named("delete-widget").SelectContext(namedCtx, &ids, "SELECT id FROM widgets")
+			Expect(err).NotTo(HaveOccurred())
+
+			name, _ := fakeMonitor.MonitorNamedArgsForCall(0)
+			Expect(name).To(Equal("select-widgets"))
+		})
+
+		It("tracks statements in a transaction under the name it was started with", func() {
+			tx, err := database.BeginTxx(monitor.WithQueryName(ctx, "move-widget"), nil)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = tx.Exec(tx.Rebind("UPDATE widgets SET name = ? WHERE id = ?"), "uno", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tx.Commit()).To(Succeed())
+
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(3))
+			for i := 0; i < 3; i++ {
+				name, _ := fakeMonitor.MonitorNamedArgsForCall(i)
+				Expect(name).To(Equal("move-widget"))
+			}
+		})
+
+		It("does not name queries made through the original wrapper", func() {
+			database.Named("delete-widget")
+			_, err := database.ExecContext(ctx, "DELETE FROM widgets")
+			Expect(err).NotTo(HaveOccurred())
+
+			name, _ := fakeMonitor.MonitorNamedArgsForCall(0)
+			Expect(name).To(BeEmpty())
+		})
+	})
+
+	Describe("slow queries", func() {
+		var (
+			logger    *lagertest.TestLogger
+			slowQuery string
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			database.Logger = logger
+			database.SlowQueryThreshold = 100 * time.Millisecond
+
+			slowQuery = "SELECT 'secret-value', SLEEP(0.2)"
+			if database.DriverName() == "postgres" {
+				slowQuery = "SELECT 'secret-value', pg_sleep(0.2)"
+			}
+		})
+
+		It("logs statements slower than the threshold without their literals and counts them", func() {
+			rows, err := database.QueryContext(ctx, slowQuery)
+			Expect(err).NotTo(HaveOccurred())
+			rows.Close()
+
+			Expect(fakeMonitor.RecordSlowQueryCallCount()).To(Equal(1))
+			Expect(logger.Logs()).To(HaveLen(1))
+			log := logger.Logs()[0]
+			Expect(log.Message).To(Equal("test.slow-query"))
+			Expect(log.Data["query"]).To(ContainSubstring("SELECT '?'"))
+			Expect(log.Data["query"]).NotTo(ContainSubstring("secret-value"))
+			Expect(log.Data["caller"]).To(ContainSubstring("conn_wrapper_test.go"))
+			Expect(log.Data).To(HaveKey("duration"))
+		})
+
+		It("logs slow statements run in a transaction", func() {
+			tx, err := database.Beginx()
+			Expect(err).NotTo(HaveOccurred())
+			_, err = tx.Exec(slowQuery)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tx.Rollback()).To(Succeed())
+
+			Expect(fakeMonitor.RecordSlowQueryCallCount()).To(Equal(1))
+			Expect(logger).To(gbytes.Say("slow-query"))
+		})
+
+		It("does not log statements faster than the threshold", func() {
+			_, err := database.ExecContext(ctx, "DELETE FROM widgets")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeMonitor.RecordSlowQueryCallCount()).To(Equal(0))
+			Expect(logger.Logs()).To(BeEmpty())
+		})
+	})
+
+	Context("when the context is cancelled", func() {
+		It("returns the context error and records it with the monitor", func() {
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+
+			_, err := database.ExecContext(cancelledCtx, "DELETE FROM widgets")
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+		})
+	})
+})
@@ -5,20 +5,60 @@ import (
 	"fmt"
 	"time"
 
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
 	"code.cloudfoundry.org/lager/v3"
 )
 
-func NewConnectionPool(conf Config,
-	maxOpenConnections int, maxIdleConnections int, connMaxLifetime time.Duration,
-	logPrefix string, jobPrefix string, logger lager.Logger,
+const (
+	DefaultConnectMaxRetries  = 10
+	DefaultConnectBackoffBase = 500 * time.Millisecond
+	DefaultConnectBackoffMax  = 5 * time.Second
+)
+
+// NewConnectionPool connects to the database, retrying for a while if it is
+// unavailable, and configures the pool from conf.PoolConfig(opts...).
+func NewConnectionPool(conf Config, logPrefix string, jobPrefix string, logger lager.Logger, opts ...PoolOption) (*ConnWrapper, error) {
+	return newConnectionPool(conf, GetConnectionPool, logPrefix, jobPrefix, logger, opts...)
+}
+
+// NewRotatingConnectionPool is NewConnectionPool for credentials that may be
+// rotated while the pool is open. Connections are opened with the credentials
+// from provider; when they change, new connections use the new credentials
+// and connections opened with the old ones are closed as they become idle.
+func NewRotatingConnectionPool(conf Config, provider CredentialsProvider, logPrefix string, jobPrefix string, logger lager.Logger, opts ...PoolOption) (*ConnWrapper, error) {
+	connector := &Connector{
+		CredentialsProvider: provider,
+		Logger:              logger,
+	}
+	return newConnectionPool(conf, connector.GetConnectionPool, logPrefix, jobPrefix, logger, opts...)
+}
+
+func newConnectionPool(conf Config, connect func(Config, context.Context) (*ConnWrapper, error),
+	logPrefix string, jobPrefix string, logger lager.Logger, opts ...PoolOption,
 ) (*ConnWrapper, error) {
+	poolConfig, err := conf.PoolConfig(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s.%s: db pool config: %s", logPrefix, jobPrefix, err)
+	}
 
+	backoff := poolConfig.ConnectBackoff
+	if backoff == nil {
+		backoff = DecorrelatedJitterBackoff{Base: DefaultConnectBackoffBase, Max: DefaultConnectBackoffMax}
+	}
+	mon := monitor.New()
 	retriableConnector := RetriableConnector{
-		Logger:        logger,
-		Connector:     GetConnectionPool,
-		Sleeper:       SleeperFunc(time.Sleep),
-		RetryInterval: time.Duration(3) * time.Second,
-		MaxRetries:    10,
+		Logger:         logger,
+		Connector:      connect,
+		Sleeper:        TimerSleeper{},
+		MaxRetries:     DefaultConnectMaxRetries,
+		Backoff:        backoff,
+		MaxElapsedTime: poolConfig.ConnectMaxElapsedTime,
+		OnRetry: func(attempt int, delay time.Duration, err error) {
+			mon.RecordConnectRetry()
+			if poolConfig.OnConnectRetry != nil {
+				poolConfig.OnConnectRetry(attempt, delay, err)
+			}
+		},
 	}
 
 	logger.Info("getting db connection", lager.Data{})
@@ -29,9 +69,9 @@ func NewConnectionPool(conf Config,
 		return nil, fmt.Errorf("%s.%s: db connect: %s", logPrefix, jobPrefix, err)
 	}
 
-	connectionPool.SetMaxOpenConns(maxOpenConnections)
-	connectionPool.SetMaxIdleConns(maxIdleConnections)
-	connectionPool.SetConnMaxLifetime(connMaxLifetime)
+	poolConfig.apply(connectionPool)
+	connectionPool.Monitor = mon
+	connectionPool.Logger = logger
 	logger.Info("db connection retrieved", lager.Data{})
 
 	return connectionPool, nil
@@ -0,0 +1,62 @@
+package db_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NewConnectionPool", func() {
+	var (
+		dbConf db.Config
+		logger *lagertest.TestLogger
+	)
+
+	BeforeEach(func() {
+		dbConf = db.Config{
+			Type:         "postgres",
+			User:         "user",
+			Password:     "password",
+			Host:         "127.0.0.1",
+			Port:         1,
+			DatabaseName: "db",
+			Timeout:      5,
+		}
+		logger = lagertest.NewTestLogger("test")
+	})
+
+	Context("when the database cannot be reached", func() {
+		It("retries with the backoff given and calls the retry hook", func() {
+			var delays []time.Duration
+			_, err := db.NewConnectionPool(dbConf, "log", "job", logger,
+				db.WithConnectBackoff(db.ConstantBackoff{Interval: time.Millisecond}),
+				db.WithConnectOnRetry(func(_ int, delay time.Duration, _ error) {
+					delays = append(delays, delay)
+				}),
+			)
+			Expect(err).To(MatchError(ContainSubstring("log.job: db connect: unable to ping")))
+			Expect(delays).To(HaveLen(db.DefaultConnectMaxRetries - 1))
+			Expect(delays).To(HaveEach(time.Millisecond))
+		})
+
+		It("stops retrying after the max elapsed time", func() {
+			var retries int
+			_, err := db.NewConnectionPool(dbConf, "log", "job", logger,
+				db.WithConnectBackoff(db.ConstantBackoff{Interval: 100 * time.Millisecond}),
+				db.WithConnectMaxElapsedTime(250*time.Millisecond),
+				db.WithConnectOnRetry(func(int, time.Duration, error) { retries++ }),
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(retries).To(Equal(2))
+		})
+
+		It("rejects a negative max elapsed time", func() {
+			_, err := db.NewConnectionPool(dbConf, "log", "job", logger, db.WithConnectMaxElapsedTime(-time.Second))
+			Expect(err).To(MatchError(ContainSubstring("connect max elapsed time must not be negative")))
+		})
+	})
+})
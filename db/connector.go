@@ -3,10 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"net"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/lager/v3"
 	"github.com/jmoiron/sqlx"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -22,32 +25,117 @@ func (r RetriableError) Error() string {
 	return fmt.Sprintf("%s: %s", r.Msg, r.Inner.Error())
 }
 
+func (r RetriableError) Unwrap() error {
+	return r.Inner
+}
+
+// Connector opens connection pools, wrapping ping errors that ErrorClassifier
+// considers transient in a RetriableError. If ErrorClassifier is nil, the
+// default classifier for the configured database type is used.
+//
+// If CredentialsProvider is set, connections are opened with the credentials
+// it provides rather than those in the Config, and are replaced when the
+// credentials change. Idle connections are checked against the provider at
+// most every CredentialsCheckInterval, which defaults to
+// DefaultCredentialsCheckInterval. Rotations are logged to Logger.
+type Connector struct {
+	ErrorClassifier          ErrorClassifier
+	CredentialsProvider      CredentialsProvider
+	CredentialsCheckInterval time.Duration
+	Logger                   lager.Logger
+}
+
 func GetConnectionPool(dbConfig Config, ctx context.Context) (*ConnWrapper, error) {
-	connectionString, err := dbConfig.ConnectionString()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create connection string: %s", err)
-	}
-	nativeDBConn, err := sql.Open(dbConfig.Type, connectionString)
+	return (&Connector{}).GetConnectionPool(dbConfig, ctx)
+}
+
+func (c *Connector) GetConnectionPool(dbConfig Config, ctx context.Context) (*ConnWrapper, error) {
+	conn, err := c.open(dbConfig)
 	if err != nil {
-		return nil, fmt.Errorf("unable to open database connection: %s", err)
+		return nil, err
 	}
 
-	dbConn := sqlx.NewDb(nativeDBConn, dbConfig.Type)
-
-	if err = dbConn.PingContext(ctx); err != nil {
+	if err = conn.PingContext(ctx); err != nil {
 		// #nosec G104 - ignore errors closing a connection that failed to ping, prefer the ping error
-		dbConn.Close()
-		if netErr, ok := err.(*net.OpError); ok {
+		conn.Close()
+		if c.classifier(dbConfig.Type).IsRetriable(err) {
 			return nil, RetriableError{
-				Inner: netErr,
+				Inner: err,
 				Msg:   "unable to ping",
 			}
 		}
 		return nil, fmt.Errorf("unable to ping: %s", err)
 	}
 
+	return conn, nil
+}
+
+// open returns a pool for dbConfig without connecting to the database.
+func (c *Connector) open(dbConfig Config) (*ConnWrapper, error) {
+	nativeDBConn, release, err := c.openDB(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ConnWrapper{
-		DB:      dbConn,
+		DB:      sqlx.NewDb(nativeDBConn, dbConfig.Type),
 		Monitor: monitor.New(),
+		release: sync.OnceFunc(release),
 	}, nil
 }
+
+func (c *Connector) classifier(driverName string) ErrorClassifier {
+	if c.ErrorClassifier == nil {
+		return ClassifierForDriver(driverName)
+	}
+	return c.ErrorClassifier
+}
+
+// openDB opens a pool against dbConfig.Host or, when failover hosts are
+// configured, a pool whose connections fail over between all of the hosts.
+// The returned function releases the driver state registered for the pool
+// and must be called once the pool is closed.
+func (c *Connector) openDB(dbConfig Config) (*sql.DB, func(), error) {
+	if c.CredentialsProvider != nil {
+		logger := c.Logger
+		if logger == nil {
+			logger = lager.NewLogger("db")
+		}
+		checkInterval := c.CredentialsCheckInterval
+		if checkInterval == 0 {
+			checkInterval = DefaultCredentialsCheckInterval
+		}
+		connector, err := newRotatingConnector(dbConfig, c.CredentialsProvider, checkInterval, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return sql.OpenDB(connector), connector.close, nil
+	}
+
+	connector, release, err := newConfigConnector(dbConfig)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sql.OpenDB(connector), release, nil
+}
+
+func newConfigConnector(dbConfig Config) (driver.Connector, func(), error) {
+	if len(dbConfig.FailoverHosts) > 0 {
+		connector, err := newFailoverConnector(dbConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return connector, connector.release, nil
+	}
+
+	connectionString, release, err := dbConfig.connectionString()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create connection string: %s", err)
+	}
+	connector, err := newDriverConnector(dbConfig.Type, connectionString)
+	if err != nil {
+		release()
+		return nil, nil, fmt.Errorf("unable to open database connection: %s", err)
+	}
+	return connector, release, nil
+}
@@ -68,6 +68,18 @@ var _ = Describe("GetConnectionPool", func() {
 		})
 	})
 
+	Context("when a custom error classifier is used", func() {
+		It("uses it to decide whether ping errors are retriable", func() {
+			testsupport.RemoveDatabase(dbConf)
+			connector := &db.Connector{
+				ErrorClassifier: db.ErrorClassifierFunc(func(error) bool { return true }),
+			}
+
+			_, err := connector.GetConnectionPool(dbConf, context.Background())
+			Expect(err).To(BeAssignableToTypeOf(db.RetriableError{}))
+		})
+	})
+
 	Context("when there is a network connectivity problem", func() {
 		It("returns a retriable error", func() {
 			dbConf.Port = 0
@@ -80,6 +92,29 @@ var _ = Describe("GetConnectionPool", func() {
 		})
 	})
 
+	Context("when failover hosts are configured", func() {
+		It("connects to the first host that is reachable", func() {
+			dbConf.FailoverHosts = []string{dbConf.Host}
+			dbConf.Host = "unreachable.invalid"
+
+			database, err := db.GetConnectionPool(dbConf, context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			defer database.Close()
+
+			var one int
+			Expect(database.QueryRow("SELECT 1").Scan(&one)).To(Succeed())
+			Expect(one).To(Equal(1))
+		})
+
+		It("returns a retriable error when no host is reachable", func() {
+			dbConf.Host = "unreachable.invalid"
+			dbConf.FailoverHosts = []string{"also-unreachable.invalid"}
+
+			_, err := db.GetConnectionPool(dbConf, context.Background())
+			Expect(err).To(BeAssignableToTypeOf(db.RetriableError{}))
+		})
+	})
+
 	It("sets the databaseConfig.Type as the DriverName", func() {
 		database, err := db.GetConnectionPool(dbConf, context.Background())
 		Expect(err).NotTo(HaveOccurred())
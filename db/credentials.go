@@ -0,0 +1,100 @@
+package db
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Credentials are the parts of a Config that may be rotated while a pool is
+// open. Empty fields leave the value from the Config unchanged.
+type Credentials struct {
+	User       string
+	Password   string
+	CACert     string
+	ClientCert string
+	ClientKey  string
+
+	// Version identifies these credentials, including the contents of any
+	// certificate files. Connections opened with an older version are
+	// drained from the pool once Version changes.
+	Version string
+}
+
+// CredentialsProvider supplies the current Credentials for a pool opened
+// with NewRotatingConnectionPool.
+type CredentialsProvider interface {
+	Credentials() (Credentials, error)
+}
+
+func (c Credentials) apply(conf Config) Config {
+	if c.User != "" {
+		conf.User = c.User
+	}
+	if c.Password != "" {
+		conf.Password = c.Password
+	}
+	if c.CACert != "" {
+		conf.CACert = c.CACert
+	}
+	if c.ClientCert != "" {
+		conf.ClientCert = c.ClientCert
+	}
+	if c.ClientKey != "" {
+		conf.ClientKey = c.ClientKey
+	}
+	return conf
+}
+
+// FileCredentialsProvider reads credentials from files on disk, such as those
+// rendered by BOSH or written by a CredHub sidecar. The user and password are
+// read from the contents of UserFile and PasswordFile, with surrounding
+// whitespace trimmed; CACert, ClientCert and ClientKey are paths passed on to
+// the driver. The version changes whenever any of the files' contents change.
+type FileCredentialsProvider struct {
+	UserFile     string
+	PasswordFile string
+	CACert       string
+	ClientCert   string
+	ClientKey    string
+}
+
+func (p *FileCredentialsProvider) Credentials() (Credentials, error) {
+	version := sha256.New()
+	read := func(path string) (string, error) {
+		if path == "" {
+			return "", nil
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("reading credentials file: %s", err)
+		}
+		fmt.Fprintf(version, "%s\x00%d\x00", path, len(contents))
+		version.Write(contents)
+		return string(contents), nil
+	}
+
+	user, err := read(p.UserFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	password, err := read(p.PasswordFile)
+	if err != nil {
+		return Credentials{}, err
+	}
+	for _, path := range []string{p.CACert, p.ClientCert, p.ClientKey} {
+		if _, err := read(path); err != nil {
+			return Credentials{}, err
+		}
+	}
+
+	return Credentials{
+		User:       strings.TrimSpace(user),
+		Password:   strings.TrimSpace(password),
+		CACert:     p.CACert,
+		ClientCert: p.ClientCert,
+		ClientKey:  p.ClientKey,
+		Version:    fmt.Sprintf("%x", version.Sum(nil)),
+	}, nil
+}
@@ -0,0 +1,238 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/onsi/gomega/gbytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeCredentialsProvider struct {
+	lock        sync.Mutex
+	credentials db.Credentials
+	err         error
+}
+
+func (p *fakeCredentialsProvider) Credentials() (db.Credentials, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.credentials, p.err
+}
+
+func (p *fakeCredentialsProvider) rotate(version string) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.credentials.Version = version
+}
+
+var _ = Describe("FileCredentialsProvider", func() {
+	var (
+		dir      string
+		provider *db.FileCredentialsProvider
+	)
+
+	write := func(name, contents string) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, []byte(contents), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		provider = &db.FileCredentialsProvider{
+			UserFile:     write("user", "some-user\n"),
+			PasswordFile: write("password", "some-password\n"),
+			CACert:       write("ca.crt", DATABASE_CA_CERT),
+		}
+	})
+
+	It("reads the user and password and passes the certificate paths through", func() {
+		credentials, err := provider.Credentials()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials.User).To(Equal("some-user"))
+		Expect(credentials.Password).To(Equal("some-password"))
+		Expect(credentials.CACert).To(Equal(filepath.Join(dir, "ca.crt")))
+		Expect(credentials.Version).NotTo(BeEmpty())
+	})
+
+	It("changes the version when the contents of any file change", func() {
+		original, err := provider.Credentials()
+		Expect(err).NotTo(HaveOccurred())
+
+		unchanged, err := provider.Credentials()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unchanged.Version).To(Equal(original.Version))
+
+		write("ca.crt", CERTIFICATE_FROM_ANOTHER_CA)
+		rotatedCA, err := provider.Credentials()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotatedCA.Version).NotTo(Equal(original.Version))
+
+		write("password", "new-password")
+		rotatedPassword, err := provider.Credentials()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rotatedPassword.Password).To(Equal("new-password"))
+		Expect(rotatedPassword.Version).NotTo(Equal(rotatedCA.Version))
+	})
+
+	It("returns an error when a file cannot be read", func() {
+		provider.PasswordFile = filepath.Join(dir, "missing")
+		_, err := provider.Credentials()
+		Expect(err).To(MatchError(HavePrefix("reading credentials file: ")))
+	})
+})
+
+var _ = Describe("Connector with a CredentialsProvider", func() {
+	var (
+		dbConf   db.Config
+		provider *fakeCredentialsProvider
+		logger   *lagertest.TestLogger
+		conn     *db.ConnWrapper
+	)
+
+	backendID := func() int64 {
+		query := "SELECT pg_backend_pid()"
+		if dbConf.Type == "mysql" {
+			query = "SELECT CONNECTION_ID()"
+		}
+		var id int64
+		Expect(conn.QueryRow(query).Scan(&id)).To(Succeed())
+		return id
+	}
+
+	BeforeEach(func() {
+		dbConf = testsupport.GetDBConfig()
+		dbConf.DatabaseName = fmt.Sprintf("test_%x", randomGenerator.Int())
+		testsupport.CreateDatabase(dbConf)
+
+		provider = &fakeCredentialsProvider{
+			credentials: db.Credentials{Password: dbConf.Password, Version: "1"},
+		}
+		dbConf.Password = "wrong-password"
+		logger = lagertest.NewTestLogger("test")
+
+		connector := &db.Connector{
+			CredentialsProvider:      provider,
+			CredentialsCheckInterval: time.Millisecond,
+			Logger:                   logger,
+		}
+		var err error
+		conn, err = connector.GetConnectionPool(dbConf, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		conn.SetMaxOpenConns(1)
+	})
+
+	AfterEach(func() {
+		conn.Close()
+		dbConf.Password = provider.credentials.Password
+		testsupport.RemoveDatabase(dbConf)
+	})
+
+	It("connects with the provided credentials", func() {
+		Expect(conn.Ping()).To(Succeed())
+	})
+
+	It("replaces idle connections once the credentials change", func() {
+		id := backendID()
+		Expect(backendID()).To(Equal(id))
+
+		provider.rotate("2")
+		time.Sleep(2 * time.Millisecond)
+
+		Expect(backendID()).NotTo(Equal(id))
+		Expect(logger).To(gbytes.Say("db-credentials-rotated"))
+	})
+
+	It("keeps the current connections when the provider fails", func() {
+		id := backendID()
+
+		provider.lock.Lock()
+		provider.err = fmt.Errorf("credhub is down")
+		provider.lock.Unlock()
+		time.Sleep(2 * time.Millisecond)
+
+		Expect(backendID()).To(Equal(id))
+		Expect(logger).To(gbytes.Say("db-credentials-unavailable"))
+	})
+})
+
+var _ = Describe("Connector with a CredentialsProvider on sqlite", func() {
+	var (
+		provider *fakeCredentialsProvider
+		logger   *lagertest.TestLogger
+		conn     *db.ConnWrapper
+	)
+
+	// temporary tables belong to a single connection, so one only remains
+	// visible while the pool keeps reusing the connection that created it
+	sameConnection := func() bool {
+		var count int
+		Expect(conn.Get(&count, "SELECT COUNT(*) FROM sqlite_temp_master WHERE name = 'marker'")).To(Succeed())
+		return count == 1
+	}
+
+	BeforeEach(func() {
+		provider = &fakeCredentialsProvider{credentials: db.Credentials{Version: "1"}}
+		logger = lagertest.NewTestLogger("test")
+
+		connector := &db.Connector{
+			CredentialsProvider:      provider,
+			CredentialsCheckInterval: time.Millisecond,
+			Logger:                   logger,
+		}
+		var err error
+		conn, err = connector.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		conn.SetMaxOpenConns(1)
+
+		_, err = conn.Exec("CREATE TEMP TABLE marker (id INTEGER)")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("keeps reusing connections while the credentials are unchanged", func() {
+		time.Sleep(2 * time.Millisecond)
+		Expect(sameConnection()).To(BeTrue())
+		Expect(sameConnection()).To(BeTrue())
+	})
+
+	It("replaces idle connections once the credentials change", func() {
+		provider.rotate("2")
+		time.Sleep(2 * time.Millisecond)
+
+		Expect(sameConnection()).To(BeFalse())
+		Expect(logger).To(gbytes.Say("db-credentials-rotated"))
+	})
+
+	It("lets a connection in use finish before replacing it", func() {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+
+		provider.rotate("2")
+		time.Sleep(2 * time.Millisecond)
+
+		var count int
+		Expect(tx.QueryRow("SELECT COUNT(*) FROM sqlite_temp_master WHERE name = 'marker'").Scan(&count)).To(Succeed())
+		Expect(count).To(Equal(1))
+		Expect(tx.Commit()).To(Succeed())
+
+		Expect(sameConnection()).To(BeFalse())
+	})
+})
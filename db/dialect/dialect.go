@@ -0,0 +1,209 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Dialect builds the SQL that differs between the supported databases. It is
+// selected by db.Config.Type, which is also the DriverName of connections and
+// transactions opened from that config.
+//
+// Statements are returned with the driver's own placeholders, so they do not
+// need to be rebound. Table and column names are written as given and must
+// not come from user input.
+type Dialect struct {
+	name string
+}
+
+var (
+	Postgres = Dialect{name: "postgres"}
+	MySQL    = Dialect{name: "mysql"}
+	SQLite   = Dialect{name: "sqlite"}
+)
+
+// New returns the Dialect for dbType.
+func New(dbType string) (Dialect, error) {
+	switch dbType {
+	case Postgres.name, MySQL.name, SQLite.name:
+		return Dialect{name: dbType}, nil
+	default:
+		return Dialect{}, fmt.Errorf("database type '%s' is not supported", dbType)
+	}
+}
+
+func (d Dialect) Name() string {
+	return d.name
+}
+
+// Placeholder returns the placeholder for the nth argument, counting from 1.
+func (d Dialect) Placeholder(n int) string {
+	if d == Postgres {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+// Insert returns an INSERT of one row into columns.
+func (d Dialect) Insert(table string, columns ...string) string {
+	placeholders := make([]string, len(columns))
+	for i := range columns {
+		placeholders[i] = d.Placeholder(i + 1)
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+}
+
+// Upsert returns an INSERT of one row into columns that, when the row
+// conflicts with an existing one on conflictColumns, sets updateColumns of the
+// existing row to the inserted values instead. With no updateColumns the
+// existing row is left unchanged.
+//
+// MySQL has no conflict target and uses whichever unique key the row
+// conflicts with, so conflictColumns must name the only unique key that can
+// conflict. An error is returned if conflictColumns is empty.
+func (d Dialect) Upsert(table string, columns []string, conflictColumns []string, updateColumns []string) (string, error) {
+	if len(conflictColumns) == 0 {
+		return "", errors.New("upsert requires at least one conflict column")
+	}
+
+	insert := d.Insert(table, columns...)
+
+	if d == MySQL {
+		if len(updateColumns) == 0 {
+			// assigning a column to itself leaves the row unchanged
+			return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s = %s", insert, conflictColumns[0], conflictColumns[0]), nil
+		}
+		assignments := make([]string, len(updateColumns))
+		for i, column := range updateColumns {
+			assignments[i] = fmt.Sprintf("%s = VALUES(%s)", column, column)
+		}
+		return fmt.Sprintf("%s ON DUPLICATE KEY UPDATE %s", insert, strings.Join(assignments, ", ")), nil
+	}
+
+	conflict := fmt.Sprintf("%s ON CONFLICT (%s)", insert, strings.Join(conflictColumns, ", "))
+	if len(updateColumns) == 0 {
+		return conflict + " DO NOTHING", nil
+	}
+	assignments := make([]string, len(updateColumns))
+	for i, column := range updateColumns {
+		assignments[i] = fmt.Sprintf("%s = excluded.%s", column, column)
+	}
+	return fmt.Sprintf("%s DO UPDATE SET %s", conflict, strings.Join(assignments, ", ")), nil
+}
+
+// InsertReturningID returns an INSERT of one row into columns and reports
+// whether the statement returns the generated idColumn as a row. If it does,
+// run it with QueryRow and scan the id; otherwise run it with Exec and read
+// the id from sql.Result.LastInsertId, as MySQL has no RETURNING clause.
+func (d Dialect) InsertReturningID(table string, columns []string, idColumn string) (string, bool) {
+	insert := d.Insert(table, columns...)
+	if d == MySQL {
+		return insert, false
+	}
+	return fmt.Sprintf("%s RETURNING %s", insert, idColumn), true
+}
+
+// ForUpdate returns the clause that locks the selected rows until the end of
+// the transaction, to append after any LIMIT. With skipLocked, rows locked by
+// other transactions are skipped rather than waited for, so that several
+// workers can claim rows from the same table; MySQL supports this from 8.0.
+//
+// SQLite locks the whole database for writing when a transaction begins, so
+// there is no row locking clause and ForUpdate returns an empty string.
+func (d Dialect) ForUpdate(skipLocked bool) string {
+	if d == SQLite {
+		return ""
+	}
+	if skipLocked {
+		return " FOR UPDATE SKIP LOCKED"
+	}
+	return " FOR UPDATE"
+}
+
+// BooleanType returns the column type used for booleans.
+func (d Dialect) BooleanType() string {
+	if d == MySQL {
+		return "TINYINT(1)"
+	}
+	return "BOOLEAN"
+}
+
+// Bool returns the literal for b.
+func (d Dialect) Bool(b bool) string {
+	switch {
+	case d == Postgres && b:
+		return "TRUE"
+	case d == Postgres:
+		return "FALSE"
+	case b:
+		return "1"
+	default:
+		return "0"
+	}
+}
+
+// JSONType returns the column type used for JSON documents.
+func (d Dialect) JSONType() string {
+	switch d {
+	case Postgres:
+		return "JSONB"
+	case MySQL:
+		return "JSON"
+	default:
+		return "TEXT"
+	}
+}
+
+// JSONExtractText returns an expression for the value at path in the JSON
+// document in column, as unquoted text. Each element of path is an object
+// key.
+func (d Dialect) JSONExtractText(column string, path ...string) string {
+	switch d {
+	case Postgres:
+		return fmt.Sprintf("%s #>> %s", column, postgresPath(path))
+	case MySQL:
+		return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", column, jsonPath(path))
+	default:
+		return fmt.Sprintf("json_extract(%s, %s)", column, jsonPath(path))
+	}
+}
+
+// JSONSet returns an expression for the JSON document in column with the
+// value at path replaced by the JSON document passed as argument n. Each
+// element of path is an object key.
+func (d Dialect) JSONSet(column string, n int, path ...string) string {
+	switch d {
+	case Postgres:
+		return fmt.Sprintf("jsonb_set(%s, %s, %s::jsonb)", column, postgresPath(path), d.Placeholder(n))
+	case MySQL:
+		return fmt.Sprintf("JSON_SET(%s, %s, CAST(? AS JSON))", column, jsonPath(path))
+	default:
+		return fmt.Sprintf("json_set(%s, %s, json(?))", column, jsonPath(path))
+	}
+}
+
+var pathKeyEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// postgresPath returns path as a text array literal, such as '{"a","b"}'.
+func postgresPath(path []string) string {
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = `"` + pathKeyEscaper.Replace(key) + `"`
+	}
+	return quote("{" + strings.Join(keys, ",") + "}")
+}
+
+// jsonPath returns path as a SQL/JSON path literal, such as '$."a"."b"'.
+func jsonPath(path []string) string {
+	p := "$"
+	for _, key := range path {
+		p += `."` + pathKeyEscaper.Replace(key) + `"`
+	}
+	return quote(p)
+}
+
+// quote returns s as a string literal.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
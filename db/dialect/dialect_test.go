@@ -0,0 +1,150 @@
+package dialect_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/dialect"
+	_ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dialect", func() {
+	Describe("New", func() {
+		It("returns the dialect for each supported database type", func() {
+			for _, d := range []dialect.Dialect{dialect.Postgres, dialect.MySQL, dialect.SQLite} {
+				found, err := dialect.New(d.Name())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(Equal(d))
+			}
+		})
+
+		It("returns an error for other database types", func() {
+			_, err := dialect.New("oracle")
+			Expect(err).To(MatchError("database type 'oracle' is not supported"))
+		})
+	})
+
+	DescribeTable("Upsert",
+		func(d dialect.Dialect, updateColumns []string, expected string) {
+			query, err := d.Upsert("policies", []string{"id", "source", "destination"}, []string{"id"}, updateColumns)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(query).To(Equal(expected))
+		},
+		Entry("postgres", dialect.Postgres, []string{"source", "destination"},
+			"INSERT INTO policies (id, source, destination) VALUES ($1, $2, $3) ON CONFLICT (id) DO UPDATE SET source = excluded.source, destination = excluded.destination"),
+		Entry("postgres without updates", dialect.Postgres, nil,
+			"INSERT INTO policies (id, source, destination) VALUES ($1, $2, $3) ON CONFLICT (id) DO NOTHING"),
+		Entry("mysql", dialect.MySQL, []string{"source", "destination"},
+			"INSERT INTO policies (id, source, destination) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE source = VALUES(source), destination = VALUES(destination)"),
+		Entry("mysql without updates", dialect.MySQL, nil,
+			"INSERT INTO policies (id, source, destination) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE id = id"),
+		Entry("sqlite", dialect.SQLite, []string{"source"},
+			"INSERT INTO policies (id, source, destination) VALUES (?, ?, ?) ON CONFLICT (id) DO UPDATE SET source = excluded.source"),
+	)
+
+	It("returns an error for an upsert without conflict columns", func() {
+		for _, d := range []dialect.Dialect{dialect.Postgres, dialect.MySQL, dialect.SQLite} {
+			_, err := d.Upsert("policies", []string{"id", "source"}, nil, []string{"source"})
+			Expect(err).To(MatchError("upsert requires at least one conflict column"))
+		}
+	})
+
+	DescribeTable("InsertReturningID",
+		func(d dialect.Dialect, expected string, returnsID bool) {
+			query, returning := d.InsertReturningID("groups", []string{"guid"}, "id")
+			Expect(query).To(Equal(expected))
+			Expect(returning).To(Equal(returnsID))
+		},
+		Entry("postgres", dialect.Postgres, "INSERT INTO groups (guid) VALUES ($1) RETURNING id", true),
+		Entry("mysql", dialect.MySQL, "INSERT INTO groups (guid) VALUES (?)", false),
+		Entry("sqlite", dialect.SQLite, "INSERT INTO groups (guid) VALUES (?) RETURNING id", true),
+	)
+
+	DescribeTable("ForUpdate",
+		func(d dialect.Dialect, expected string, expectedSkipLocked string) {
+			Expect(d.ForUpdate(false)).To(Equal(expected))
+			Expect(d.ForUpdate(true)).To(Equal(expectedSkipLocked))
+		},
+		Entry("postgres", dialect.Postgres, " FOR UPDATE", " FOR UPDATE SKIP LOCKED"),
+		Entry("mysql", dialect.MySQL, " FOR UPDATE", " FOR UPDATE SKIP LOCKED"),
+		Entry("sqlite", dialect.SQLite, "", ""),
+	)
+
+	DescribeTable("types and literals",
+		func(d dialect.Dialect, booleanType, trueLiteral, falseLiteral, jsonType string) {
+			Expect(d.BooleanType()).To(Equal(booleanType))
+			Expect(d.Bool(true)).To(Equal(trueLiteral))
+			Expect(d.Bool(false)).To(Equal(falseLiteral))
+			Expect(d.JSONType()).To(Equal(jsonType))
+		},
+		Entry("postgres", dialect.Postgres, "BOOLEAN", "TRUE", "FALSE", "JSONB"),
+		Entry("mysql", dialect.MySQL, "TINYINT(1)", "1", "0", "JSON"),
+		Entry("sqlite", dialect.SQLite, "BOOLEAN", "1", "0", "TEXT"),
+	)
+
+	DescribeTable("JSONExtractText",
+		func(d dialect.Dialect, expected string) {
+			Expect(d.JSONExtractText("metadata", "labels", "app's")).To(Equal(expected))
+		},
+		Entry("postgres", dialect.Postgres, `metadata #>> '{"labels","app''s"}'`),
+		Entry("mysql", dialect.MySQL, `JSON_UNQUOTE(JSON_EXTRACT(metadata, '$."labels"."app''s"'))`),
+		Entry("sqlite", dialect.SQLite, `json_extract(metadata, '$."labels"."app''s"')`),
+	)
+
+	DescribeTable("JSONSet",
+		func(d dialect.Dialect, expected string) {
+			Expect(d.JSONSet("metadata", 2, "labels")).To(Equal(expected))
+		},
+		Entry("postgres", dialect.Postgres, `jsonb_set(metadata, '{"labels"}', $2::jsonb)`),
+		Entry("mysql", dialect.MySQL, `JSON_SET(metadata, '$."labels"', CAST(? AS JSON))`),
+		Entry("sqlite", dialect.SQLite, `json_set(metadata, '$."labels"', json(?))`),
+	)
+
+	Describe("running the sqlite statements", func() {
+		var conn *db.ConnWrapper
+
+		BeforeEach(func() {
+			var err error
+			conn, err = db.GetConnectionPool(db.Config{
+				Type:         "sqlite",
+				DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+				Timeout:      5,
+			}, context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Exec("CREATE TABLE groups (id INTEGER PRIMARY KEY AUTOINCREMENT, guid TEXT UNIQUE, active " + dialect.SQLite.BooleanType() + ", metadata " + dialect.SQLite.JSONType() + ")")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(conn.Close()).To(Succeed())
+		})
+
+		It("produces valid SQL", func() {
+			d, err := dialect.New(conn.DriverName())
+			Expect(err).NotTo(HaveOccurred())
+
+			query, returning := d.InsertReturningID("groups", []string{"guid", "active", "metadata"}, "id")
+			Expect(returning).To(BeTrue())
+			var id int64
+			Expect(conn.QueryRow(query, "some-guid", true, `{"labels":{"app":"a"}}`).Scan(&id)).To(Succeed())
+			Expect(id).To(BeEquivalentTo(1))
+
+			upsert, err := d.Upsert("groups", []string{"guid", "active"}, []string{"guid"}, []string{"active"})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = conn.Exec(upsert, "some-guid", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Exec("UPDATE groups SET metadata = "+d.JSONSet("metadata", 1, "labels")+" WHERE id = ?", `{"app":"b"}`, id)
+			Expect(err).NotTo(HaveOccurred())
+
+			var app string
+			Expect(conn.Get(&app, "SELECT "+d.JSONExtractText("metadata", "labels", "app")+" FROM groups WHERE active = "+d.Bool(false)+" LIMIT 1"+d.ForUpdate(true))).To(Succeed())
+			Expect(app).To(Equal("b"))
+		})
+	})
+})
@@ -0,0 +1 @@
+package dialect // import "code.cloudfoundry.org/cf-networking-helpers/db/dialect"
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/lib/pq/pqerror"
+)
+
+const (
+	mysqlErrUnknownCommand   = 1047 // returned by Galera nodes that are not ready for queries
+	mysqlErrTooManyConns     = 1040
+	mysqlErrServerShutdown   = 1053
+	mysqlErrUserTooManyConns = 1203
+)
+
+// ErrorClassifier decides whether an error returned while connecting to or
+// querying the database is transient, so that the operation may succeed if
+// it is retried.
+type ErrorClassifier interface {
+	IsRetriable(error) bool
+}
+
+type ErrorClassifierFunc func(error) bool
+
+func (f ErrorClassifierFunc) IsRetriable(err error) bool {
+	return f(err)
+}
+
+// ClassifierForDriver returns the default ErrorClassifier for the given
+// db.Config.Type. Every driver treats network errors, DNS failures, timeouts
+// and broken connections as retriable, but not cancelled or expired
+// contexts.
+func ClassifierForDriver(driverName string) ErrorClassifier {
+	switch driverName {
+	case "postgres":
+		return ErrorClassifierFunc(isRetriablePostgresError)
+	case "mysql":
+		return ErrorClassifierFunc(isRetriableMySQLError)
+	default:
+		return ErrorClassifierFunc(isRetriableNetworkError)
+	}
+}
+
+func isRetriableNetworkError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func isRetriablePostgresError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case pqErr.Code == pqerror.CannotConnectNow,
+			pqErr.Code == pqerror.TooManyConnections,
+			pqErr.Code == pqerror.AdminShutdown,
+			pqErr.Code.Class() == pqerror.ClassConnectionException:
+			return true
+		}
+		return false
+	}
+	return isRetriableNetworkError(err)
+}
+
+func isRetriableMySQLError(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case mysqlErrTooManyConns, mysqlErrUserTooManyConns, mysqlErrServerShutdown, mysqlErrUnknownCommand:
+			return true
+		}
+		return false
+	}
+	return errors.Is(err, mysql.ErrInvalidConn) || isRetriableNetworkError(err)
+}
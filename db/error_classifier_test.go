@@ -0,0 +1,96 @@
+package db_test
+
+import (
+	"context"
+	"crypto/tls"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "tls: handshake timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+var _ = Describe("ErrorClassifier", func() {
+	var (
+		opErr  = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+		dnsErr = &net.DNSError{Err: "no such host", Name: "db.invalid", IsNotFound: true}
+	)
+
+	DescribeTable("common errors for every driver",
+		func(err error, expected bool) {
+			for _, driverName := range []string{"postgres", "mysql", "other"} {
+				Expect(db.ClassifierForDriver(driverName).IsRetriable(err)).To(Equal(expected), driverName)
+			}
+		},
+		Entry("network error", opErr, true),
+		Entry("wrapped network error", fmt.Errorf("ping: %w", opErr), true),
+		Entry("dns failure", dnsErr, true),
+		Entry("tls handshake timeout", timeoutError{}, true),
+		Entry("bad connection", driver.ErrBadConn, true),
+		Entry("unexpected EOF", io.ErrUnexpectedEOF, true),
+		Entry("context cancelled", context.Canceled, false),
+		Entry("context deadline exceeded", context.DeadlineExceeded, false),
+		Entry("tls certificate error", tls.RecordHeaderError{Msg: "bad record"}, false),
+		Entry("generic error", errors.New("banana"), false),
+		Entry("nil", nil, false),
+	)
+
+	DescribeTable("postgres errors",
+		func(err error, expected bool) {
+			Expect(db.ClassifierForDriver("postgres").IsRetriable(err)).To(Equal(expected))
+		},
+		Entry("cannot_connect_now", &pq.Error{Code: "57P03"}, true),
+		Entry("too_many_connections", &pq.Error{Code: "53300"}, true),
+		Entry("admin_shutdown", &pq.Error{Code: "57P01"}, true),
+		Entry("connection_failure", &pq.Error{Code: "08006"}, true),
+		Entry("invalid_catalog_name", &pq.Error{Code: "3D000"}, false),
+		Entry("invalid_password", &pq.Error{Code: "28P01"}, false),
+		Entry("mysql too many connections", &mysql.MySQLError{Number: 1040}, false),
+	)
+
+	DescribeTable("mysql errors",
+		func(err error, expected bool) {
+			Expect(db.ClassifierForDriver("mysql").IsRetriable(err)).To(Equal(expected))
+		},
+		Entry("too many connections", &mysql.MySQLError{Number: 1040}, true),
+		Entry("user has too many connections", &mysql.MySQLError{Number: 1203}, true),
+		Entry("server shutdown", &mysql.MySQLError{Number: 1053}, true),
+		Entry("galera node not ready", &mysql.MySQLError{Number: 1047}, true),
+		Entry("invalid connection", mysql.ErrInvalidConn, true),
+		Entry("unknown database", &mysql.MySQLError{Number: 1049}, false),
+		Entry("access denied", &mysql.MySQLError{Number: 1045}, false),
+		Entry("postgres cannot_connect_now", &pq.Error{Code: "57P03"}, false),
+	)
+
+	It("can be replaced by a custom function", func() {
+		classifier := db.ErrorClassifierFunc(func(err error) bool {
+			return err.Error() == "try again"
+		})
+		Expect(classifier.IsRetriable(errors.New("try again"))).To(BeTrue())
+		Expect(classifier.IsRetriable(opErr)).To(BeFalse())
+	})
+})
+
+var _ = Describe("RetriableError", func() {
+	It("unwraps to the inner error", func() {
+		inner := &pq.Error{Code: "57P03"}
+		err := db.RetriableError{Inner: inner, Msg: "unable to ping"}
+
+		var pqErr *pq.Error
+		Expect(errors.As(err, &pqErr)).To(BeTrue())
+		Expect(pqErr).To(Equal(inner))
+	})
+})
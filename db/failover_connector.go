@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sync"
+)
+
+// failoverConnector opens each new connection against the first host that
+// accepts it. It starts from the host that last accepted a connection, so a
+// pool keeps using a node until it fails and then moves on to the next one.
+type failoverConnector struct {
+	driver     driver.Driver
+	connectors []driver.Connector
+	releases   []func()
+
+	lock    sync.Mutex
+	current int
+}
+
+func newFailoverConnector(dbConfig Config) (*failoverConnector, error) {
+	failover := &failoverConnector{}
+	for _, host := range dbConfig.AllHosts() {
+		hostConfig := dbConfig
+		hostConfig.Host = host
+		hostConfig.FailoverHosts = nil
+
+		connectionString, release, err := hostConfig.connectionString()
+		if err != nil {
+			failover.release()
+			return nil, fmt.Errorf("failed to create connection string: %s", err)
+		}
+		failover.releases = append(failover.releases, release)
+
+		connector, err := newDriverConnector(dbConfig.Type, connectionString)
+		if err != nil {
+			failover.release()
+			return nil, fmt.Errorf("unable to open database connection: %s", err)
+		}
+		failover.driver = connector.Driver()
+		failover.connectors = append(failover.connectors, connector)
+	}
+	return failover, nil
+}
+
+func (f *failoverConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	f.lock.Lock()
+	start := f.current
+	f.lock.Unlock()
+
+	var lastErr error
+	for i := 0; i < len(f.connectors); i++ {
+		index := (start + i) % len(f.connectors)
+		conn, err := f.connectors[index].Connect(ctx)
+		if err == nil {
+			f.lock.Lock()
+			f.current = index
+			f.lock.Unlock()
+			return conn, nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return nil, lastErr
+}
+
+func (f *failoverConnector) Driver() driver.Driver {
+	return f.driver
+}
+
+func (f *failoverConnector) release() {
+	for _, release := range f.releases {
+		release()
+	}
+}
+
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (d *dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return d.driver.Open(d.dsn)
+}
+
+func (d *dsnConnector) Driver() driver.Driver {
+	return d.driver
+}
+
+func newDriverConnector(driverName string, dsn string) (driver.Connector, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	d := db.Driver()
+	// #nosec G104 - nothing has been opened yet, this only discards the handle used to look up the driver
+	db.Close()
+
+	if driverContext, ok := d.(driver.DriverContext); ok {
+		return driverContext.OpenConnector(dsn)
+	}
+	return &dsnConnector{driver: d, dsn: dsn}, nil
+}
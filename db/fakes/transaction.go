@@ -2,6 +2,7 @@
 package fakes
 
 import (
+	"context"
 	"database/sql"
 	"sync"
 
@@ -44,6 +45,77 @@ type Transaction struct {
 		result1 sql.Result
 		result2 error
 	}
+	ExecContextStub        func(context.Context, string, ...interface{}) (sql.Result, error)
+	execContextMutex       sync.RWMutex
+	execContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []interface{}
+	}
+	execContextReturns struct {
+		result1 sql.Result
+		result2 error
+	}
+	execContextReturnsOnCall map[int]struct {
+		result1 sql.Result
+		result2 error
+	}
+	GetStub        func(interface{}, string, ...interface{}) error
+	getMutex       sync.RWMutex
+	getArgsForCall []struct {
+		arg1 interface{}
+		arg2 string
+		arg3 []interface{}
+	}
+	getReturns struct {
+		result1 error
+	}
+	getReturnsOnCall map[int]struct {
+		result1 error
+	}
+	GetContextStub        func(context.Context, interface{}, string, ...interface{}) error
+	getContextMutex       sync.RWMutex
+	getContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 interface{}
+		arg3 string
+		arg4 []interface{}
+	}
+	getContextReturns struct {
+		result1 error
+	}
+	getContextReturnsOnCall map[int]struct {
+		result1 error
+	}
+	NamedExecStub        func(string, interface{}) (sql.Result, error)
+	namedExecMutex       sync.RWMutex
+	namedExecArgsForCall []struct {
+		arg1 string
+		arg2 interface{}
+	}
+	namedExecReturns struct {
+		result1 sql.Result
+		result2 error
+	}
+	namedExecReturnsOnCall map[int]struct {
+		result1 sql.Result
+		result2 error
+	}
+	NamedExecContextStub        func(context.Context, string, interface{}) (sql.Result, error)
+	namedExecContextMutex       sync.RWMutex
+	namedExecContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 interface{}
+	}
+	namedExecContextReturns struct {
+		result1 sql.Result
+		result2 error
+	}
+	namedExecContextReturnsOnCall map[int]struct {
+		result1 sql.Result
+		result2 error
+	}
 	QueryRowStub        func(string, ...interface{}) db.RowScanner
 	queryRowMutex       sync.RWMutex
 	queryRowArgsForCall []struct {
@@ -56,6 +128,19 @@ type Transaction struct {
 	queryRowReturnsOnCall map[int]struct {
 		result1 db.RowScanner
 	}
+	QueryRowContextStub        func(context.Context, string, ...interface{}) db.RowScanner
+	queryRowContextMutex       sync.RWMutex
+	queryRowContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []interface{}
+	}
+	queryRowContextReturns struct {
+		result1 db.RowScanner
+	}
+	queryRowContextReturnsOnCall map[int]struct {
+		result1 db.RowScanner
+	}
 	QueryxStub        func(string, ...interface{}) (*sqlx.Rows, error)
 	queryxMutex       sync.RWMutex
 	queryxArgsForCall []struct {
@@ -70,6 +155,21 @@ type Transaction struct {
 		result1 *sqlx.Rows
 		result2 error
 	}
+	QueryxContextStub        func(context.Context, string, ...interface{}) (*sqlx.Rows, error)
+	queryxContextMutex       sync.RWMutex
+	queryxContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []interface{}
+	}
+	queryxContextReturns struct {
+		result1 *sqlx.Rows
+		result2 error
+	}
+	queryxContextReturnsOnCall map[int]struct {
+		result1 *sqlx.Rows
+		result2 error
+	}
 	RebindStub        func(string) string
 	rebindMutex       sync.RWMutex
 	rebindArgsForCall []struct {
@@ -81,6 +181,17 @@ type Transaction struct {
 	rebindReturnsOnCall map[int]struct {
 		result1 string
 	}
+	ReleaseStub        func(string) error
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		arg1 string
+	}
+	releaseReturns struct {
+		result1 error
+	}
+	releaseReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RollbackStub        func() error
 	rollbackMutex       sync.RWMutex
 	rollbackArgsForCall []struct {
@@ -91,6 +202,55 @@ type Transaction struct {
 	rollbackReturnsOnCall map[int]struct {
 		result1 error
 	}
+	RollbackToStub        func(string) error
+	rollbackToMutex       sync.RWMutex
+	rollbackToArgsForCall []struct {
+		arg1 string
+	}
+	rollbackToReturns struct {
+		result1 error
+	}
+	rollbackToReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SavepointStub        func(string) error
+	savepointMutex       sync.RWMutex
+	savepointArgsForCall []struct {
+		arg1 string
+	}
+	savepointReturns struct {
+		result1 error
+	}
+	savepointReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SelectStub        func(interface{}, string, ...interface{}) error
+	selectMutex       sync.RWMutex
+	selectArgsForCall []struct {
+		arg1 interface{}
+		arg2 string
+		arg3 []interface{}
+	}
+	selectReturns struct {
+		result1 error
+	}
+	selectReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SelectContextStub        func(context.Context, interface{}, string, ...interface{}) error
+	selectContextMutex       sync.RWMutex
+	selectContextArgsForCall []struct {
+		arg1 context.Context
+		arg2 interface{}
+		arg3 string
+		arg4 []interface{}
+	}
+	selectContextReturns struct {
+		result1 error
+	}
+	selectContextReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -266,6 +426,330 @@ func (fake *Transaction) ExecReturnsOnCall(i int, result1 sql.Result, result2 er
 	}{result1, result2}
 }
 
+func (fake *Transaction) ExecContext(arg1 context.Context, arg2 string, arg3 ...interface{}) (sql.Result, error) {
+	fake.execContextMutex.Lock()
+	ret, specificReturn := fake.execContextReturnsOnCall[len(fake.execContextArgsForCall)]
+	fake.execContextArgsForCall = append(fake.execContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.ExecContextStub
+	fakeReturns := fake.execContextReturns
+	fake.recordInvocation("ExecContext", []interface{}{arg1, arg2, arg3})
+	fake.execContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Transaction) ExecContextCallCount() int {
+	fake.execContextMutex.RLock()
+	defer fake.execContextMutex.RUnlock()
+	return len(fake.execContextArgsForCall)
+}
+
+func (fake *Transaction) ExecContextCalls(stub func(context.Context, string, ...interface{}) (sql.Result, error)) {
+	fake.execContextMutex.Lock()
+	defer fake.execContextMutex.Unlock()
+	fake.ExecContextStub = stub
+}
+
+func (fake *Transaction) ExecContextArgsForCall(i int) (context.Context, string, []interface{}) {
+	fake.execContextMutex.RLock()
+	defer fake.execContextMutex.RUnlock()
+	argsForCall := fake.execContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Transaction) ExecContextReturns(result1 sql.Result, result2 error) {
+	fake.execContextMutex.Lock()
+	defer fake.execContextMutex.Unlock()
+	fake.ExecContextStub = nil
+	fake.execContextReturns = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Transaction) ExecContextReturnsOnCall(i int, result1 sql.Result, result2 error) {
+	fake.execContextMutex.Lock()
+	defer fake.execContextMutex.Unlock()
+	fake.ExecContextStub = nil
+	if fake.execContextReturnsOnCall == nil {
+		fake.execContextReturnsOnCall = make(map[int]struct {
+			result1 sql.Result
+			result2 error
+		})
+	}
+	fake.execContextReturnsOnCall[i] = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Transaction) Get(arg1 interface{}, arg2 string, arg3 ...interface{}) error {
+	fake.getMutex.Lock()
+	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
+	fake.getArgsForCall = append(fake.getArgsForCall, struct {
+		arg1 interface{}
+		arg2 string
+		arg3 []interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.GetStub
+	fakeReturns := fake.getReturns
+	fake.recordInvocation("Get", []interface{}{arg1, arg2, arg3})
+	fake.getMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) GetCallCount() int {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	return len(fake.getArgsForCall)
+}
+
+func (fake *Transaction) GetCalls(stub func(interface{}, string, ...interface{}) error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = stub
+}
+
+func (fake *Transaction) GetArgsForCall(i int) (interface{}, string, []interface{}) {
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	argsForCall := fake.getArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Transaction) GetReturns(result1 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	fake.getReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) GetReturnsOnCall(i int, result1 error) {
+	fake.getMutex.Lock()
+	defer fake.getMutex.Unlock()
+	fake.GetStub = nil
+	if fake.getReturnsOnCall == nil {
+		fake.getReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) GetContext(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...interface{}) error {
+	fake.getContextMutex.Lock()
+	ret, specificReturn := fake.getContextReturnsOnCall[len(fake.getContextArgsForCall)]
+	fake.getContextArgsForCall = append(fake.getContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 interface{}
+		arg3 string
+		arg4 []interface{}
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.GetContextStub
+	fakeReturns := fake.getContextReturns
+	fake.recordInvocation("GetContext", []interface{}{arg1, arg2, arg3, arg4})
+	fake.getContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) GetContextCallCount() int {
+	fake.getContextMutex.RLock()
+	defer fake.getContextMutex.RUnlock()
+	return len(fake.getContextArgsForCall)
+}
+
+func (fake *Transaction) GetContextCalls(stub func(context.Context, interface{}, string, ...interface{}) error) {
+	fake.getContextMutex.Lock()
+	defer fake.getContextMutex.Unlock()
+	fake.GetContextStub = stub
+}
+
+func (fake *Transaction) GetContextArgsForCall(i int) (context.Context, interface{}, string, []interface{}) {
+	fake.getContextMutex.RLock()
+	defer fake.getContextMutex.RUnlock()
+	argsForCall := fake.getContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Transaction) GetContextReturns(result1 error) {
+	fake.getContextMutex.Lock()
+	defer fake.getContextMutex.Unlock()
+	fake.GetContextStub = nil
+	fake.getContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) GetContextReturnsOnCall(i int, result1 error) {
+	fake.getContextMutex.Lock()
+	defer fake.getContextMutex.Unlock()
+	fake.GetContextStub = nil
+	if fake.getContextReturnsOnCall == nil {
+		fake.getContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.getContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) NamedExec(arg1 string, arg2 interface{}) (sql.Result, error) {
+	fake.namedExecMutex.Lock()
+	ret, specificReturn := fake.namedExecReturnsOnCall[len(fake.namedExecArgsForCall)]
+	fake.namedExecArgsForCall = append(fake.namedExecArgsForCall, struct {
+		arg1 string
+		arg2 interface{}
+	}{arg1, arg2})
+	stub := fake.NamedExecStub
+	fakeReturns := fake.namedExecReturns
+	fake.recordInvocation("NamedExec", []interface{}{arg1, arg2})
+	fake.namedExecMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Transaction) NamedExecCallCount() int {
+	fake.namedExecMutex.RLock()
+	defer fake.namedExecMutex.RUnlock()
+	return len(fake.namedExecArgsForCall)
+}
+
+func (fake *Transaction) NamedExecCalls(stub func(string, interface{}) (sql.Result, error)) {
+	fake.namedExecMutex.Lock()
+	defer fake.namedExecMutex.Unlock()
+	fake.NamedExecStub = stub
+}
+
+func (fake *Transaction) NamedExecArgsForCall(i int) (string, interface{}) {
+	fake.namedExecMutex.RLock()
+	defer fake.namedExecMutex.RUnlock()
+	argsForCall := fake.namedExecArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Transaction) NamedExecReturns(result1 sql.Result, result2 error) {
+	fake.namedExecMutex.Lock()
+	defer fake.namedExecMutex.Unlock()
+	fake.NamedExecStub = nil
+	fake.namedExecReturns = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Transaction) NamedExecReturnsOnCall(i int, result1 sql.Result, result2 error) {
+	fake.namedExecMutex.Lock()
+	defer fake.namedExecMutex.Unlock()
+	fake.NamedExecStub = nil
+	if fake.namedExecReturnsOnCall == nil {
+		fake.namedExecReturnsOnCall = make(map[int]struct {
+			result1 sql.Result
+			result2 error
+		})
+	}
+	fake.namedExecReturnsOnCall[i] = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Transaction) NamedExecContext(arg1 context.Context, arg2 string, arg3 interface{}) (sql.Result, error) {
+	fake.namedExecContextMutex.Lock()
+	ret, specificReturn := fake.namedExecContextReturnsOnCall[len(fake.namedExecContextArgsForCall)]
+	fake.namedExecContextArgsForCall = append(fake.namedExecContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.NamedExecContextStub
+	fakeReturns := fake.namedExecContextReturns
+	fake.recordInvocation("NamedExecContext", []interface{}{arg1, arg2, arg3})
+	fake.namedExecContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Transaction) NamedExecContextCallCount() int {
+	fake.namedExecContextMutex.RLock()
+	defer fake.namedExecContextMutex.RUnlock()
+	return len(fake.namedExecContextArgsForCall)
+}
+
+func (fake *Transaction) NamedExecContextCalls(stub func(context.Context, string, interface{}) (sql.Result, error)) {
+	fake.namedExecContextMutex.Lock()
+	defer fake.namedExecContextMutex.Unlock()
+	fake.NamedExecContextStub = stub
+}
+
+func (fake *Transaction) NamedExecContextArgsForCall(i int) (context.Context, string, interface{}) {
+	fake.namedExecContextMutex.RLock()
+	defer fake.namedExecContextMutex.RUnlock()
+	argsForCall := fake.namedExecContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Transaction) NamedExecContextReturns(result1 sql.Result, result2 error) {
+	fake.namedExecContextMutex.Lock()
+	defer fake.namedExecContextMutex.Unlock()
+	fake.NamedExecContextStub = nil
+	fake.namedExecContextReturns = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Transaction) NamedExecContextReturnsOnCall(i int, result1 sql.Result, result2 error) {
+	fake.namedExecContextMutex.Lock()
+	defer fake.namedExecContextMutex.Unlock()
+	fake.NamedExecContextStub = nil
+	if fake.namedExecContextReturnsOnCall == nil {
+		fake.namedExecContextReturnsOnCall = make(map[int]struct {
+			result1 sql.Result
+			result2 error
+		})
+	}
+	fake.namedExecContextReturnsOnCall[i] = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Transaction) QueryRow(arg1 string, arg2 ...interface{}) db.RowScanner {
 	fake.queryRowMutex.Lock()
 	ret, specificReturn := fake.queryRowReturnsOnCall[len(fake.queryRowArgsForCall)]
@@ -328,6 +812,69 @@ func (fake *Transaction) QueryRowReturnsOnCall(i int, result1 db.RowScanner) {
 	}{result1}
 }
 
+func (fake *Transaction) QueryRowContext(arg1 context.Context, arg2 string, arg3 ...interface{}) db.RowScanner {
+	fake.queryRowContextMutex.Lock()
+	ret, specificReturn := fake.queryRowContextReturnsOnCall[len(fake.queryRowContextArgsForCall)]
+	fake.queryRowContextArgsForCall = append(fake.queryRowContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.QueryRowContextStub
+	fakeReturns := fake.queryRowContextReturns
+	fake.recordInvocation("QueryRowContext", []interface{}{arg1, arg2, arg3})
+	fake.queryRowContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) QueryRowContextCallCount() int {
+	fake.queryRowContextMutex.RLock()
+	defer fake.queryRowContextMutex.RUnlock()
+	return len(fake.queryRowContextArgsForCall)
+}
+
+func (fake *Transaction) QueryRowContextCalls(stub func(context.Context, string, ...interface{}) db.RowScanner) {
+	fake.queryRowContextMutex.Lock()
+	defer fake.queryRowContextMutex.Unlock()
+	fake.QueryRowContextStub = stub
+}
+
+func (fake *Transaction) QueryRowContextArgsForCall(i int) (context.Context, string, []interface{}) {
+	fake.queryRowContextMutex.RLock()
+	defer fake.queryRowContextMutex.RUnlock()
+	argsForCall := fake.queryRowContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Transaction) QueryRowContextReturns(result1 db.RowScanner) {
+	fake.queryRowContextMutex.Lock()
+	defer fake.queryRowContextMutex.Unlock()
+	fake.QueryRowContextStub = nil
+	fake.queryRowContextReturns = struct {
+		result1 db.RowScanner
+	}{result1}
+}
+
+func (fake *Transaction) QueryRowContextReturnsOnCall(i int, result1 db.RowScanner) {
+	fake.queryRowContextMutex.Lock()
+	defer fake.queryRowContextMutex.Unlock()
+	fake.QueryRowContextStub = nil
+	if fake.queryRowContextReturnsOnCall == nil {
+		fake.queryRowContextReturnsOnCall = make(map[int]struct {
+			result1 db.RowScanner
+		})
+	}
+	fake.queryRowContextReturnsOnCall[i] = struct {
+		result1 db.RowScanner
+	}{result1}
+}
+
 func (fake *Transaction) Queryx(arg1 string, arg2 ...interface{}) (*sqlx.Rows, error) {
 	fake.queryxMutex.Lock()
 	ret, specificReturn := fake.queryxReturnsOnCall[len(fake.queryxArgsForCall)]
@@ -393,6 +940,72 @@ func (fake *Transaction) QueryxReturnsOnCall(i int, result1 *sqlx.Rows, result2
 	}{result1, result2}
 }
 
+func (fake *Transaction) QueryxContext(arg1 context.Context, arg2 string, arg3 ...interface{}) (*sqlx.Rows, error) {
+	fake.queryxContextMutex.Lock()
+	ret, specificReturn := fake.queryxContextReturnsOnCall[len(fake.queryxContextArgsForCall)]
+	fake.queryxContextArgsForCall = append(fake.queryxContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 []interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.QueryxContextStub
+	fakeReturns := fake.queryxContextReturns
+	fake.recordInvocation("QueryxContext", []interface{}{arg1, arg2, arg3})
+	fake.queryxContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Transaction) QueryxContextCallCount() int {
+	fake.queryxContextMutex.RLock()
+	defer fake.queryxContextMutex.RUnlock()
+	return len(fake.queryxContextArgsForCall)
+}
+
+func (fake *Transaction) QueryxContextCalls(stub func(context.Context, string, ...interface{}) (*sqlx.Rows, error)) {
+	fake.queryxContextMutex.Lock()
+	defer fake.queryxContextMutex.Unlock()
+	fake.QueryxContextStub = stub
+}
+
+func (fake *Transaction) QueryxContextArgsForCall(i int) (context.Context, string, []interface{}) {
+	fake.queryxContextMutex.RLock()
+	defer fake.queryxContextMutex.RUnlock()
+	argsForCall := fake.queryxContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Transaction) QueryxContextReturns(result1 *sqlx.Rows, result2 error) {
+	fake.queryxContextMutex.Lock()
+	defer fake.queryxContextMutex.Unlock()
+	fake.QueryxContextStub = nil
+	fake.queryxContextReturns = struct {
+		result1 *sqlx.Rows
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Transaction) QueryxContextReturnsOnCall(i int, result1 *sqlx.Rows, result2 error) {
+	fake.queryxContextMutex.Lock()
+	defer fake.queryxContextMutex.Unlock()
+	fake.QueryxContextStub = nil
+	if fake.queryxContextReturnsOnCall == nil {
+		fake.queryxContextReturnsOnCall = make(map[int]struct {
+			result1 *sqlx.Rows
+			result2 error
+		})
+	}
+	fake.queryxContextReturnsOnCall[i] = struct {
+		result1 *sqlx.Rows
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Transaction) Rebind(arg1 string) string {
 	fake.rebindMutex.Lock()
 	ret, specificReturn := fake.rebindReturnsOnCall[len(fake.rebindArgsForCall)]
@@ -454,6 +1067,67 @@ func (fake *Transaction) RebindReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *Transaction) Release(arg1 string) error {
+	fake.releaseMutex.Lock()
+	ret, specificReturn := fake.releaseReturnsOnCall[len(fake.releaseArgsForCall)]
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReleaseStub
+	fakeReturns := fake.releaseReturns
+	fake.recordInvocation("Release", []interface{}{arg1})
+	fake.releaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *Transaction) ReleaseCalls(stub func(string) error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = stub
+}
+
+func (fake *Transaction) ReleaseArgsForCall(i int) string {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	argsForCall := fake.releaseArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Transaction) ReleaseReturns(result1 error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = nil
+	fake.releaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) ReleaseReturnsOnCall(i int, result1 error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = nil
+	if fake.releaseReturnsOnCall == nil {
+		fake.releaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Transaction) Rollback() error {
 	fake.rollbackMutex.Lock()
 	ret, specificReturn := fake.rollbackReturnsOnCall[len(fake.rollbackArgsForCall)]
@@ -507,6 +1181,255 @@ func (fake *Transaction) RollbackReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Transaction) RollbackTo(arg1 string) error {
+	fake.rollbackToMutex.Lock()
+	ret, specificReturn := fake.rollbackToReturnsOnCall[len(fake.rollbackToArgsForCall)]
+	fake.rollbackToArgsForCall = append(fake.rollbackToArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RollbackToStub
+	fakeReturns := fake.rollbackToReturns
+	fake.recordInvocation("RollbackTo", []interface{}{arg1})
+	fake.rollbackToMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) RollbackToCallCount() int {
+	fake.rollbackToMutex.RLock()
+	defer fake.rollbackToMutex.RUnlock()
+	return len(fake.rollbackToArgsForCall)
+}
+
+func (fake *Transaction) RollbackToCalls(stub func(string) error) {
+	fake.rollbackToMutex.Lock()
+	defer fake.rollbackToMutex.Unlock()
+	fake.RollbackToStub = stub
+}
+
+func (fake *Transaction) RollbackToArgsForCall(i int) string {
+	fake.rollbackToMutex.RLock()
+	defer fake.rollbackToMutex.RUnlock()
+	argsForCall := fake.rollbackToArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Transaction) RollbackToReturns(result1 error) {
+	fake.rollbackToMutex.Lock()
+	defer fake.rollbackToMutex.Unlock()
+	fake.RollbackToStub = nil
+	fake.rollbackToReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) RollbackToReturnsOnCall(i int, result1 error) {
+	fake.rollbackToMutex.Lock()
+	defer fake.rollbackToMutex.Unlock()
+	fake.RollbackToStub = nil
+	if fake.rollbackToReturnsOnCall == nil {
+		fake.rollbackToReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rollbackToReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) Savepoint(arg1 string) error {
+	fake.savepointMutex.Lock()
+	ret, specificReturn := fake.savepointReturnsOnCall[len(fake.savepointArgsForCall)]
+	fake.savepointArgsForCall = append(fake.savepointArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.SavepointStub
+	fakeReturns := fake.savepointReturns
+	fake.recordInvocation("Savepoint", []interface{}{arg1})
+	fake.savepointMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) SavepointCallCount() int {
+	fake.savepointMutex.RLock()
+	defer fake.savepointMutex.RUnlock()
+	return len(fake.savepointArgsForCall)
+}
+
+func (fake *Transaction) SavepointCalls(stub func(string) error) {
+	fake.savepointMutex.Lock()
+	defer fake.savepointMutex.Unlock()
+	fake.SavepointStub = stub
+}
+
+func (fake *Transaction) SavepointArgsForCall(i int) string {
+	fake.savepointMutex.RLock()
+	defer fake.savepointMutex.RUnlock()
+	argsForCall := fake.savepointArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Transaction) SavepointReturns(result1 error) {
+	fake.savepointMutex.Lock()
+	defer fake.savepointMutex.Unlock()
+	fake.SavepointStub = nil
+	fake.savepointReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) SavepointReturnsOnCall(i int, result1 error) {
+	fake.savepointMutex.Lock()
+	defer fake.savepointMutex.Unlock()
+	fake.SavepointStub = nil
+	if fake.savepointReturnsOnCall == nil {
+		fake.savepointReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.savepointReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) Select(arg1 interface{}, arg2 string, arg3 ...interface{}) error {
+	fake.selectMutex.Lock()
+	ret, specificReturn := fake.selectReturnsOnCall[len(fake.selectArgsForCall)]
+	fake.selectArgsForCall = append(fake.selectArgsForCall, struct {
+		arg1 interface{}
+		arg2 string
+		arg3 []interface{}
+	}{arg1, arg2, arg3})
+	stub := fake.SelectStub
+	fakeReturns := fake.selectReturns
+	fake.recordInvocation("Select", []interface{}{arg1, arg2, arg3})
+	fake.selectMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) SelectCallCount() int {
+	fake.selectMutex.RLock()
+	defer fake.selectMutex.RUnlock()
+	return len(fake.selectArgsForCall)
+}
+
+func (fake *Transaction) SelectCalls(stub func(interface{}, string, ...interface{}) error) {
+	fake.selectMutex.Lock()
+	defer fake.selectMutex.Unlock()
+	fake.SelectStub = stub
+}
+
+func (fake *Transaction) SelectArgsForCall(i int) (interface{}, string, []interface{}) {
+	fake.selectMutex.RLock()
+	defer fake.selectMutex.RUnlock()
+	argsForCall := fake.selectArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Transaction) SelectReturns(result1 error) {
+	fake.selectMutex.Lock()
+	defer fake.selectMutex.Unlock()
+	fake.SelectStub = nil
+	fake.selectReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) SelectReturnsOnCall(i int, result1 error) {
+	fake.selectMutex.Lock()
+	defer fake.selectMutex.Unlock()
+	fake.SelectStub = nil
+	if fake.selectReturnsOnCall == nil {
+		fake.selectReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.selectReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) SelectContext(arg1 context.Context, arg2 interface{}, arg3 string, arg4 ...interface{}) error {
+	fake.selectContextMutex.Lock()
+	ret, specificReturn := fake.selectContextReturnsOnCall[len(fake.selectContextArgsForCall)]
+	fake.selectContextArgsForCall = append(fake.selectContextArgsForCall, struct {
+		arg1 context.Context
+		arg2 interface{}
+		arg3 string
+		arg4 []interface{}
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.SelectContextStub
+	fakeReturns := fake.selectContextReturns
+	fake.recordInvocation("SelectContext", []interface{}{arg1, arg2, arg3, arg4})
+	fake.selectContextMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4...)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Transaction) SelectContextCallCount() int {
+	fake.selectContextMutex.RLock()
+	defer fake.selectContextMutex.RUnlock()
+	return len(fake.selectContextArgsForCall)
+}
+
+func (fake *Transaction) SelectContextCalls(stub func(context.Context, interface{}, string, ...interface{}) error) {
+	fake.selectContextMutex.Lock()
+	defer fake.selectContextMutex.Unlock()
+	fake.SelectContextStub = stub
+}
+
+func (fake *Transaction) SelectContextArgsForCall(i int) (context.Context, interface{}, string, []interface{}) {
+	fake.selectContextMutex.RLock()
+	defer fake.selectContextMutex.RUnlock()
+	argsForCall := fake.selectContextArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Transaction) SelectContextReturns(result1 error) {
+	fake.selectContextMutex.Lock()
+	defer fake.selectContextMutex.Unlock()
+	fake.SelectContextStub = nil
+	fake.selectContextReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Transaction) SelectContextReturnsOnCall(i int, result1 error) {
+	fake.selectContextMutex.Lock()
+	defer fake.selectContextMutex.Unlock()
+	fake.SelectContextStub = nil
+	if fake.selectContextReturnsOnCall == nil {
+		fake.selectContextReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.selectContextReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Transaction) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -516,14 +1439,38 @@ func (fake *Transaction) Invocations() map[string][][]interface{} {
 	defer fake.driverNameMutex.RUnlock()
 	fake.execMutex.RLock()
 	defer fake.execMutex.RUnlock()
+	fake.execContextMutex.RLock()
+	defer fake.execContextMutex.RUnlock()
+	fake.getMutex.RLock()
+	defer fake.getMutex.RUnlock()
+	fake.getContextMutex.RLock()
+	defer fake.getContextMutex.RUnlock()
+	fake.namedExecMutex.RLock()
+	defer fake.namedExecMutex.RUnlock()
+	fake.namedExecContextMutex.RLock()
+	defer fake.namedExecContextMutex.RUnlock()
 	fake.queryRowMutex.RLock()
 	defer fake.queryRowMutex.RUnlock()
+	fake.queryRowContextMutex.RLock()
+	defer fake.queryRowContextMutex.RUnlock()
 	fake.queryxMutex.RLock()
 	defer fake.queryxMutex.RUnlock()
+	fake.queryxContextMutex.RLock()
+	defer fake.queryxContextMutex.RUnlock()
 	fake.rebindMutex.RLock()
 	defer fake.rebindMutex.RUnlock()
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
 	fake.rollbackMutex.RLock()
 	defer fake.rollbackMutex.RUnlock()
+	fake.rollbackToMutex.RLock()
+	defer fake.rollbackToMutex.RUnlock()
+	fake.savepointMutex.RLock()
+	defer fake.savepointMutex.RUnlock()
+	fake.selectMutex.RLock()
+	defer fake.selectMutex.RUnlock()
+	fake.selectContextMutex.RLock()
+	defer fake.selectContextMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const DefaultHealthCheckInterval = 10 * time.Second
+
+type pinger interface {
+	PingContext(ctx context.Context) error
+}
+
+// HealthChecker is an ifrit runner that pings DB every Interval, or every
+// DefaultHealthCheckInterval if Interval is not positive. The first
+// check decides the initial state; after that the database is reported
+// unhealthy after UnhealthyThreshold consecutive failed pings and healthy
+// again after HealthyThreshold consecutive successful ones. Both thresholds
+// default to 1. A ping that takes longer than Timeout, or than the interval
+// if Timeout is not positive, fails.
+//
+// HealthChecker is also an http.Handler that responds 200 while the database
+// is healthy and 503 otherwise, for use as a readiness check.
+type HealthChecker struct {
+	Logger             lager.Logger
+	DB                 pinger
+	Interval           time.Duration
+	Timeout            time.Duration
+	HealthyThreshold   int
+	UnhealthyThreshold int
+
+	lock      sync.RWMutex
+	checked   bool
+	healthy   bool
+	successes int
+	failures  int
+}
+
+func (h *HealthChecker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	interval := h.Interval
+	if interval <= 0 {
+		interval = DefaultHealthCheckInterval
+	}
+
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = interval
+	}
+
+	h.check(timeout)
+	close(ready)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+			h.check(timeout)
+		}
+	}
+}
+
+func (h *HealthChecker) Healthy() bool {
+	h.lock.RLock()
+	defer h.lock.RUnlock()
+	return h.healthy
+}
+
+func (h *HealthChecker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !h.Healthy() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *HealthChecker) check(timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := h.DB.PingContext(ctx)
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	wasHealthy := h.healthy
+	if err == nil {
+		h.successes++
+		h.failures = 0
+		if !h.checked || h.successes >= threshold(h.HealthyThreshold) {
+			h.healthy = true
+		}
+	} else {
+		h.failures++
+		h.successes = 0
+		if !h.checked || h.failures >= threshold(h.UnhealthyThreshold) {
+			h.healthy = false
+		}
+	}
+
+	switch {
+	case !h.checked:
+		h.Logger.Info("db-health-initial", lager.Data{"healthy": h.healthy})
+	case wasHealthy && !h.healthy:
+		h.Logger.Error("db-became-unhealthy", err, lager.Data{"consecutive_failures": h.failures})
+	case !wasHealthy && h.healthy:
+		h.Logger.Info("db-became-healthy", lager.Data{"consecutive_successes": h.successes})
+	}
+	h.checked = true
+}
+
+func threshold(t int) int {
+	if t < 1 {
+		return 1
+	}
+	return t
+}
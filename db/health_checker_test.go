@@ -0,0 +1,157 @@
+package db_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakePinger struct {
+	lock    sync.Mutex
+	results []error
+	calls   int
+}
+
+func (p *fakePinger) PingContext(context.Context) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.calls++
+	if len(p.results) == 0 {
+		return nil
+	}
+	err := p.results[0]
+	if len(p.results) > 1 {
+		p.results = p.results[1:]
+	}
+	return err
+}
+
+func (p *fakePinger) Calls() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.calls
+}
+
+// blockingPinger hangs until the context of the ping is done, as a ping to
+// an unresponsive database would.
+type blockingPinger struct{}
+
+func (blockingPinger) PingContext(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+var _ = Describe("HealthChecker", func() {
+	var (
+		pinger  *fakePinger
+		logger  *lagertest.TestLogger
+		checker *db.HealthChecker
+		process ifrit.Process
+		pingErr error
+	)
+
+	BeforeEach(func() {
+		pingErr = errors.New("connection refused")
+		pinger = &fakePinger{}
+		logger = lagertest.NewTestLogger("test")
+		checker = &db.HealthChecker{
+			Logger:             logger,
+			DB:                 pinger,
+			Interval:           10 * time.Millisecond,
+			UnhealthyThreshold: 2,
+			HealthyThreshold:   2,
+		}
+	})
+
+	AfterEach(func() {
+		if process != nil {
+			process.Signal(nil)
+			Eventually(process.Wait()).Should(Receive())
+			process = nil
+		}
+	})
+
+	It("checks the database before becoming ready", func() {
+		pinger.results = []error{pingErr}
+		process = ifrit.Invoke(checker)
+
+		Expect(pinger.Calls()).To(BeNumerically(">=", 1))
+		Expect(checker.Healthy()).To(BeFalse())
+		Expect(logger).To(gbytes.Say("db-health-initial.*\"healthy\":false"))
+	})
+
+	It("becomes unhealthy only after the unhealthy threshold of failed pings", func() {
+		pinger.results = []error{nil, pingErr, nil, pingErr, pingErr}
+		process = ifrit.Invoke(checker)
+
+		Eventually(logger).Should(gbytes.Say("db-became-unhealthy"))
+		Expect(checker.Healthy()).To(BeFalse())
+		Expect(pinger.Calls()).To(BeNumerically(">=", 5))
+	})
+
+	It("becomes healthy again only after the healthy threshold of successful pings", func() {
+		pinger.results = []error{pingErr, nil, pingErr, nil, nil}
+		process = ifrit.Invoke(checker)
+
+		Eventually(logger).Should(gbytes.Say("db-became-healthy"))
+		Expect(checker.Healthy()).To(BeTrue())
+		Expect(pinger.Calls()).To(BeNumerically(">=", 5))
+	})
+
+	It("checks at the default interval when none is set", func() {
+		checker.Interval = 0
+		process = ifrit.Invoke(checker)
+
+		Consistently(pinger.Calls, "100ms").Should(Equal(1))
+	})
+
+	Context("when a ping hangs", func() {
+		BeforeEach(func() {
+			checker.DB = blockingPinger{}
+			checker.Interval = 50 * time.Millisecond
+		})
+
+		It("gives up on it after the interval when no timeout is set", func() {
+			process = ifrit.Background(checker)
+			Eventually(process.Ready(), "1s").Should(BeClosed())
+			Expect(checker.Healthy()).To(BeFalse())
+
+			process.Signal(nil)
+			Eventually(process.Wait(), "1s").Should(Receive(BeNil()))
+			process = nil
+		})
+
+		It("gives up on it after the timeout", func() {
+			checker.Interval = time.Hour
+			checker.Timeout = 50 * time.Millisecond
+			process = ifrit.Background(checker)
+			Eventually(process.Ready(), "1s").Should(BeClosed())
+			Expect(checker.Healthy()).To(BeFalse())
+		})
+	})
+
+	It("serves the health state as a readiness check", func() {
+		pinger.results = []error{nil, pingErr}
+		process = ifrit.Invoke(checker)
+
+		recorder := httptest.NewRecorder()
+		checker.ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+
+		Eventually(checker.Healthy).Should(BeFalse())
+		recorder = httptest.NewRecorder()
+		checker.ServeHTTP(recorder, httptest.NewRequest("GET", "/ready", nil))
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+	})
+})
@@ -0,0 +1,162 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+)
+
+// AdvisoryLock is a Locker using postgres session advisory locks or MySQL
+// named locks. The lock belongs to a database session, so while it is held
+// AdvisoryLock keeps one connection out of the pool, and the lock is lost if
+// that connection is.
+type AdvisoryLock struct {
+	conn   *db.ConnWrapper
+	dbType string
+	name   string
+
+	lock sync.Mutex
+	held *sql.Conn
+}
+
+func NewAdvisoryLock(conn *db.ConnWrapper, name string) (*AdvisoryLock, error) {
+	switch conn.DriverName() {
+	case "postgres", "mysql":
+	default:
+		return nil, fmt.Errorf("database type '%s' does not support advisory locks", conn.DriverName())
+	}
+	return &AdvisoryLock{conn: conn, dbType: conn.DriverName(), name: name}, nil
+}
+
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	return l.acquire(ctx, false)
+}
+
+// Acquire waits for the lock until it is taken or ctx is done. MySQL only
+// waits in whole seconds, so it may give up up to a second after the
+// deadline of ctx.
+func (l *AdvisoryLock) Acquire(ctx context.Context) error {
+	acquired, err := l.acquire(ctx, true)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return fmt.Errorf("acquiring lock %q: timed out", l.name)
+	}
+	return nil
+}
+
+func (l *AdvisoryLock) acquire(ctx context.Context, wait bool) (bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.held != nil {
+		return true, nil
+	}
+
+	conn, err := l.conn.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquiring lock %q: %s", l.name, err)
+	}
+
+	acquired := true
+	switch {
+	case l.dbType == "postgres" && wait:
+		_, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", l.key())
+	case l.dbType == "postgres":
+		err = conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key()).Scan(&acquired)
+	case l.dbType == "mysql":
+		var result sql.NullInt64
+		err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", l.name, mysqlLockWait(ctx, wait)).Scan(&result)
+		acquired = result.Valid && result.Int64 == 1
+	}
+	if err != nil || !acquired {
+		// #nosec G104 - returns the connection to the pool, the lock was not taken on it
+		conn.Close()
+		if err != nil {
+			return false, fmt.Errorf("acquiring lock %q: %s", l.name, err)
+		}
+		return false, nil
+	}
+
+	l.held = conn
+	return true, nil
+}
+
+// mysqlLockWait returns the GET_LOCK timeout in seconds: 0 to not wait, the
+// time left until the deadline of ctx rounded up, or -1 to wait forever.
+func mysqlLockWait(ctx context.Context, wait bool) int64 {
+	if !wait {
+		return 0
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return -1
+	}
+	// 0 would give up at once
+	return max(int64((time.Until(deadline)+time.Second-1)/time.Second), 1)
+}
+
+// Conn returns the connection holding the lock, or nil if it is not held.
+// Statements run on it are run in the session holding the lock, so they do
+// not need another connection from the pool.
+func (l *AdvisoryLock) Conn() *sql.Conn {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.held
+}
+
+// Renew checks that the session holding the lock is still alive. Session
+// locks do not expire, so there is nothing to extend.
+func (l *AdvisoryLock) Renew(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.held == nil {
+		return ErrLockLost
+	}
+	if err := l.held.PingContext(ctx); err != nil {
+		// #nosec G104 - the session is gone, and the lock with it
+		l.held.Close()
+		l.held = nil
+		return fmt.Errorf("%w: %q: %s", ErrLockLost, l.name, err)
+	}
+	return nil
+}
+
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.held == nil {
+		return nil
+	}
+
+	var err error
+	switch l.dbType {
+	case "postgres":
+		_, err = l.held.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key())
+	case "mysql":
+		_, err = l.held.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", l.name)
+	}
+	closeErr := l.held.Close()
+	l.held = nil
+	if err != nil {
+		return fmt.Errorf("releasing lock %q: %s", l.name, err)
+	}
+	return closeErr
+}
+
+// key maps the lock name onto the bigint key space used by postgres
+// advisory locks.
+func (l *AdvisoryLock) key() int64 {
+	h := fnv.New64a()
+	h.Write([]byte(l.name))
+	// #nosec G115 - wrapping into the signed range is intended, any stable value will do
+	return int64(h.Sum64())
+}
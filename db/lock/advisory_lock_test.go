@@ -0,0 +1,111 @@
+package lock_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/lock"
+	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AdvisoryLock", func() {
+	var (
+		dbConf db.Config
+		conn   *db.ConnWrapper
+		ctx    context.Context
+		lockA  *lock.AdvisoryLock
+		lockB  *lock.AdvisoryLock
+	)
+
+	BeforeEach(func() {
+		dbConf = testsupport.GetDBConfig()
+		dbConf.DatabaseName = fmt.Sprintf("test_%x", randomGenerator.Int())
+		testsupport.CreateDatabase(dbConf)
+
+		var err error
+		ctx = context.Background()
+		conn, err = db.GetConnectionPool(dbConf, ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		lockA, err = lock.NewAdvisoryLock(conn, "some-lock")
+		Expect(err).NotTo(HaveOccurred())
+		lockB, err = lock.NewAdvisoryLock(conn, "some-lock")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(lockA.Release(ctx)).To(Succeed())
+		Expect(lockB.Release(ctx)).To(Succeed())
+		Expect(conn.Close()).To(Succeed())
+		testsupport.RemoveDatabase(dbConf)
+	})
+
+	// killHolder ends the database session holding the lock, as a database
+	// restart or failover would.
+	killHolder := func() {
+		switch dbConf.Type {
+		case "postgres":
+			var killed []bool
+			Expect(conn.Select(&killed, `SELECT pg_terminate_backend(pid) FROM pg_locks
+				WHERE locktype = 'advisory' AND database = (SELECT oid FROM pg_database WHERE datname = current_database())`)).To(Succeed())
+			Expect(killed).To(Equal([]bool{true}))
+		case "mysql":
+			var id int64
+			Expect(conn.Get(&id, "SELECT IS_USED_LOCK(?)", "some-lock")).To(Succeed())
+			_, err := conn.Exec(fmt.Sprintf("KILL %d", id))
+			Expect(err).NotTo(HaveOccurred())
+		}
+	}
+
+	It("is held by only one session at a time", func() {
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockB.TryAcquire(ctx)).To(BeFalse())
+
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockA.Renew(ctx)).To(Succeed())
+	})
+
+	It("can be acquired by another session once released", func() {
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockA.Release(ctx)).To(Succeed())
+
+		Expect(lockB.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockA.TryAcquire(ctx)).To(BeFalse())
+	})
+
+	Describe("Acquire", func() {
+		It("waits until the lock is released", func() {
+			Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+
+			acquired := make(chan error, 1)
+			go func() { acquired <- lockB.Acquire(ctx) }()
+			Consistently(acquired, 200*time.Millisecond).ShouldNot(Receive())
+
+			Expect(lockA.Release(ctx)).To(Succeed())
+			Eventually(acquired, 5*time.Second).Should(Receive(BeNil()))
+			Expect(lockA.TryAcquire(ctx)).To(BeFalse())
+		})
+
+		It("gives up once the context is done", func() {
+			Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+
+			timeoutCtx, cancel := context.WithTimeout(ctx, 500*time.Millisecond)
+			defer cancel()
+			Expect(lockB.Acquire(timeoutCtx)).To(MatchError(ContainSubstring(`acquiring lock "some-lock"`)))
+		})
+	})
+
+	It("is lost when the session holding it ends", func() {
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+
+		killHolder()
+
+		Eventually(func() error { return lockA.Renew(ctx) }).Should(MatchError(lock.ErrLockLost))
+		Eventually(func() (bool, error) { return lockB.TryAcquire(ctx) }).Should(BeTrue())
+	})
+})
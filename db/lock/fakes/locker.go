@@ -0,0 +1,265 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/lock"
+)
+
+type Locker struct {
+	ReleaseStub        func(context.Context) error
+	releaseMutex       sync.RWMutex
+	releaseArgsForCall []struct {
+		arg1 context.Context
+	}
+	releaseReturns struct {
+		result1 error
+	}
+	releaseReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RenewStub        func(context.Context) error
+	renewMutex       sync.RWMutex
+	renewArgsForCall []struct {
+		arg1 context.Context
+	}
+	renewReturns struct {
+		result1 error
+	}
+	renewReturnsOnCall map[int]struct {
+		result1 error
+	}
+	TryAcquireStub        func(context.Context) (bool, error)
+	tryAcquireMutex       sync.RWMutex
+	tryAcquireArgsForCall []struct {
+		arg1 context.Context
+	}
+	tryAcquireReturns struct {
+		result1 bool
+		result2 error
+	}
+	tryAcquireReturnsOnCall map[int]struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *Locker) Release(arg1 context.Context) error {
+	fake.releaseMutex.Lock()
+	ret, specificReturn := fake.releaseReturnsOnCall[len(fake.releaseArgsForCall)]
+	fake.releaseArgsForCall = append(fake.releaseArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.ReleaseStub
+	fakeReturns := fake.releaseReturns
+	fake.recordInvocation("Release", []interface{}{arg1})
+	fake.releaseMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Locker) ReleaseCallCount() int {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	return len(fake.releaseArgsForCall)
+}
+
+func (fake *Locker) ReleaseCalls(stub func(context.Context) error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = stub
+}
+
+func (fake *Locker) ReleaseArgsForCall(i int) context.Context {
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	argsForCall := fake.releaseArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Locker) ReleaseReturns(result1 error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = nil
+	fake.releaseReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Locker) ReleaseReturnsOnCall(i int, result1 error) {
+	fake.releaseMutex.Lock()
+	defer fake.releaseMutex.Unlock()
+	fake.ReleaseStub = nil
+	if fake.releaseReturnsOnCall == nil {
+		fake.releaseReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Locker) Renew(arg1 context.Context) error {
+	fake.renewMutex.Lock()
+	ret, specificReturn := fake.renewReturnsOnCall[len(fake.renewArgsForCall)]
+	fake.renewArgsForCall = append(fake.renewArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.RenewStub
+	fakeReturns := fake.renewReturns
+	fake.recordInvocation("Renew", []interface{}{arg1})
+	fake.renewMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Locker) RenewCallCount() int {
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	return len(fake.renewArgsForCall)
+}
+
+func (fake *Locker) RenewCalls(stub func(context.Context) error) {
+	fake.renewMutex.Lock()
+	defer fake.renewMutex.Unlock()
+	fake.RenewStub = stub
+}
+
+func (fake *Locker) RenewArgsForCall(i int) context.Context {
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	argsForCall := fake.renewArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Locker) RenewReturns(result1 error) {
+	fake.renewMutex.Lock()
+	defer fake.renewMutex.Unlock()
+	fake.RenewStub = nil
+	fake.renewReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Locker) RenewReturnsOnCall(i int, result1 error) {
+	fake.renewMutex.Lock()
+	defer fake.renewMutex.Unlock()
+	fake.RenewStub = nil
+	if fake.renewReturnsOnCall == nil {
+		fake.renewReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.renewReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Locker) TryAcquire(arg1 context.Context) (bool, error) {
+	fake.tryAcquireMutex.Lock()
+	ret, specificReturn := fake.tryAcquireReturnsOnCall[len(fake.tryAcquireArgsForCall)]
+	fake.tryAcquireArgsForCall = append(fake.tryAcquireArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.TryAcquireStub
+	fakeReturns := fake.tryAcquireReturns
+	fake.recordInvocation("TryAcquire", []interface{}{arg1})
+	fake.tryAcquireMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Locker) TryAcquireCallCount() int {
+	fake.tryAcquireMutex.RLock()
+	defer fake.tryAcquireMutex.RUnlock()
+	return len(fake.tryAcquireArgsForCall)
+}
+
+func (fake *Locker) TryAcquireCalls(stub func(context.Context) (bool, error)) {
+	fake.tryAcquireMutex.Lock()
+	defer fake.tryAcquireMutex.Unlock()
+	fake.TryAcquireStub = stub
+}
+
+func (fake *Locker) TryAcquireArgsForCall(i int) context.Context {
+	fake.tryAcquireMutex.RLock()
+	defer fake.tryAcquireMutex.RUnlock()
+	argsForCall := fake.tryAcquireArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Locker) TryAcquireReturns(result1 bool, result2 error) {
+	fake.tryAcquireMutex.Lock()
+	defer fake.tryAcquireMutex.Unlock()
+	fake.TryAcquireStub = nil
+	fake.tryAcquireReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Locker) TryAcquireReturnsOnCall(i int, result1 bool, result2 error) {
+	fake.tryAcquireMutex.Lock()
+	defer fake.tryAcquireMutex.Unlock()
+	fake.TryAcquireStub = nil
+	if fake.tryAcquireReturnsOnCall == nil {
+		fake.tryAcquireReturnsOnCall = make(map[int]struct {
+			result1 bool
+			result2 error
+		})
+	}
+	fake.tryAcquireReturnsOnCall[i] = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Locker) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.releaseMutex.RLock()
+	defer fake.releaseMutex.RUnlock()
+	fake.renewMutex.RLock()
+	defer fake.renewMutex.RUnlock()
+	fake.tryAcquireMutex.RLock()
+	defer fake.tryAcquireMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *Locker) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ lock.Locker = new(Locker)
@@ -0,0 +1,107 @@
+package lock
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const (
+	DefaultLeaderRetryInterval = 5 * time.Second
+	DefaultLeaderRenewInterval = 5 * time.Second
+)
+
+// Leader is an ifrit runner that becomes ready once it holds Lock, trying to
+// acquire it every RetryInterval, and then renews it every RenewInterval.
+// Intervals that are not positive are replaced with
+// DefaultLeaderRetryInterval and DefaultLeaderRenewInterval; a LeaseLock
+// needs a RenewInterval well within its TTL. Put it ahead of the active
+// members of an ordered group so that only the leader runs them.
+//
+// If the lock cannot be renewed, Run releases it and exits with the error
+// from Renew, which wraps ErrLockLost when the lock is known to be lost, so
+// that the group shuts down and the job can be restarted to compete again.
+// When signalled, Run releases the lock and exits cleanly.
+type Leader struct {
+	Logger        lager.Logger
+	Lock          Locker
+	RetryInterval time.Duration
+	RenewInterval time.Duration
+}
+
+func (l *Leader) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	logger := l.Logger.Session("leader")
+
+	for {
+		acquired, err := l.tryAcquire()
+		if err != nil {
+			logger.Error("failed-to-acquire-lock", err)
+		}
+		if acquired {
+			break
+		}
+
+		select {
+		case <-signals:
+			return nil
+		case <-time.After(l.retryInterval()):
+		}
+	}
+
+	logger.Info("acquired-lock")
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			l.release(logger)
+			return nil
+		case <-time.After(l.renewInterval()):
+			if err := l.renew(); err != nil {
+				logger.Error("lost-lock", err)
+				l.release(logger)
+				return err
+			}
+		}
+	}
+}
+
+func (l *Leader) tryAcquire() (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.retryInterval())
+	defer cancel()
+	return l.Lock.TryAcquire(ctx)
+}
+
+// renew gives up once RenewInterval passes, so that a hung database is
+// treated as a lost lock rather than blocking the leader indefinitely.
+func (l *Leader) renew() error {
+	ctx, cancel := context.WithTimeout(context.Background(), l.renewInterval())
+	defer cancel()
+	return l.Lock.Renew(ctx)
+}
+
+func (l *Leader) release(logger lager.Logger) {
+	ctx, cancel := context.WithTimeout(context.Background(), l.renewInterval())
+	defer cancel()
+	if err := l.Lock.Release(ctx); err != nil {
+		logger.Error("failed-to-release-lock", err)
+		return
+	}
+	logger.Info("released-lock")
+}
+
+func (l *Leader) retryInterval() time.Duration {
+	if l.RetryInterval <= 0 {
+		return DefaultLeaderRetryInterval
+	}
+	return l.RetryInterval
+}
+
+func (l *Leader) renewInterval() time.Duration {
+	if l.RenewInterval <= 0 {
+		return DefaultLeaderRenewInterval
+	}
+	return l.RenewInterval
+}
@@ -0,0 +1,154 @@
+package lock_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/lock"
+	"code.cloudfoundry.org/cf-networking-helpers/db/lock/fakes"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Leader", func() {
+	var (
+		locker  *fakes.Locker
+		logger  *lagertest.TestLogger
+		leader  *lock.Leader
+		process ifrit.Process
+	)
+
+	BeforeEach(func() {
+		locker = &fakes.Locker{}
+		logger = lagertest.NewTestLogger("test")
+		leader = &lock.Leader{
+			Logger:        logger,
+			Lock:          locker,
+			RetryInterval: 10 * time.Millisecond,
+			RenewInterval: 10 * time.Millisecond,
+		}
+	})
+
+	AfterEach(func() {
+		if process != nil {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive())
+		}
+	})
+
+	It("becomes ready once it acquires the lock", func() {
+		locker.TryAcquireReturnsOnCall(0, false, errors.New("connection refused"))
+		locker.TryAcquireReturnsOnCall(1, false, nil)
+		locker.TryAcquireReturnsOnCall(2, true, nil)
+
+		process = ifrit.Background(leader)
+		Eventually(process.Ready()).Should(BeClosed())
+
+		Expect(locker.TryAcquireCallCount()).To(Equal(3))
+		Expect(logger).To(gbytes.Say("failed-to-acquire-lock.*connection refused"))
+		Expect(logger).To(gbytes.Say("acquired-lock"))
+	})
+
+	It("renews the lock while it holds it", func() {
+		locker.TryAcquireReturns(true, nil)
+		process = ifrit.Invoke(leader)
+
+		Eventually(locker.RenewCallCount).Should(BeNumerically(">=", 2))
+		ctx := locker.RenewArgsForCall(0)
+		_, hasDeadline := ctx.Deadline()
+		Expect(hasDeadline).To(BeTrue())
+	})
+
+	It("releases the lock and exits cleanly when signalled", func() {
+		locker.TryAcquireReturns(true, nil)
+		process = ifrit.Invoke(leader)
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		process = nil
+
+		Expect(locker.ReleaseCallCount()).To(Equal(1))
+		Expect(logger).To(gbytes.Say("released-lock"))
+	})
+
+	It("exits without becoming ready when signalled before acquiring the lock", func() {
+		locker.TryAcquireReturns(false, nil)
+		process = ifrit.Background(leader)
+
+		Eventually(locker.TryAcquireCallCount).Should(BeNumerically(">=", 1))
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(process.Ready()).NotTo(BeClosed())
+		process = nil
+
+		Expect(locker.ReleaseCallCount()).To(Equal(0))
+	})
+
+	Context("when the intervals are not set", func() {
+		BeforeEach(func() {
+			leader.RetryInterval = 0
+			leader.RenewInterval = 0
+		})
+
+		It("waits the default retry interval between attempts", func() {
+			locker.TryAcquireReturns(false, nil)
+			process = ifrit.Background(leader)
+
+			Eventually(locker.TryAcquireCallCount).Should(Equal(1))
+			Consistently(locker.TryAcquireCallCount, 100*time.Millisecond).Should(Equal(1))
+
+			deadline, _ := locker.TryAcquireArgsForCall(0).Deadline()
+			Expect(time.Until(deadline)).To(BeNumerically(">", lock.DefaultLeaderRetryInterval-time.Second))
+		})
+
+		It("waits the default renew interval between renewals", func() {
+			locker.TryAcquireReturns(true, nil)
+			process = ifrit.Invoke(leader)
+
+			Consistently(locker.RenewCallCount, 100*time.Millisecond).Should(Equal(0))
+		})
+	})
+
+	Context("when the lock is lost", func() {
+		BeforeEach(func() {
+			locker.TryAcquireReturns(true, nil)
+			locker.RenewReturns(fmt.Errorf("%w: lease %q", lock.ErrLockLost, "some-lock"))
+		})
+
+		It("releases the lock and exits with the error from Renew", func() {
+			process = ifrit.Invoke(leader)
+
+			var err error
+			Eventually(process.Wait()).Should(Receive(&err))
+			Expect(err).To(MatchError(lock.ErrLockLost))
+			Expect(err).To(MatchError(`lock lost: lease "some-lock"`))
+			process = nil
+
+			Expect(locker.ReleaseCallCount()).To(Equal(1))
+			Expect(logger).To(gbytes.Say("lost-lock"))
+		})
+	})
+
+	Context("when releasing the lock fails", func() {
+		BeforeEach(func() {
+			locker.TryAcquireReturns(true, nil)
+			locker.ReleaseReturns(errors.New("connection refused"))
+		})
+
+		It("logs the error", func() {
+			process = ifrit.Invoke(leader)
+
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			process = nil
+
+			Expect(logger).To(gbytes.Say("failed-to-release-lock.*connection refused"))
+		})
+	})
+})
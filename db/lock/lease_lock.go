@@ -0,0 +1,175 @@
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/dialect"
+	uuid "github.com/nu7hatch/gouuid"
+)
+
+const DefaultLeaseTableName = "leases"
+
+// LeaseLock is a Locker backed by a row in a lease table, for databases or
+// proxies where session locks cannot be used. The lease expires TTL after it
+// was last acquired or renewed, after which another owner may take it, so it
+// must be renewed well within TTL. Expiry times come from the clocks of the
+// competing VMs, which are assumed to be kept in sync.
+//
+// The lease table is created if it does not exist.
+type LeaseLock struct {
+	conn      *db.ConnWrapper
+	dialect   dialect.Dialect
+	name      string
+	owner     string
+	ttl       time.Duration
+	tableName string
+
+	lock         sync.Mutex
+	tableCreated bool
+}
+
+// NewLeaseLock returns a LeaseLock for the lease called name. An empty owner
+// is replaced with a random one; an empty tableName with
+// DefaultLeaseTableName.
+func NewLeaseLock(conn *db.ConnWrapper, name string, owner string, ttl time.Duration, tableName string) (*LeaseLock, error) {
+	d, err := dialect.New(conn.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	if owner == "" {
+		id, err := uuid.NewV4()
+		if err != nil {
+			return nil, fmt.Errorf("generating lease owner: %s", err)
+		}
+		owner = id.String()
+	}
+	if tableName == "" {
+		tableName = DefaultLeaseTableName
+	}
+	return &LeaseLock{
+		conn:      conn,
+		dialect:   d,
+		name:      name,
+		owner:     owner,
+		ttl:       ttl,
+		tableName: tableName,
+	}, nil
+}
+
+// Owner returns the owner written to the lease table while the lock is held.
+func (l *LeaseLock) Owner() string {
+	return l.owner
+}
+
+func (l *LeaseLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if err := l.createTable(ctx); err != nil {
+		return false, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(l.ttl).UnixMilli()
+	_, err := l.conn.ExecContext(ctx,
+		l.conn.Rebind(fmt.Sprintf("UPDATE %s SET owner = ?, expires_at = ? WHERE name = ? AND (owner = ? OR expires_at < ?)", l.tableName)),
+		l.owner, expiresAt, l.name, l.owner, now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("acquiring lease %q: %s", l.name, err)
+	}
+
+	upsert, err := l.dialect.Upsert(l.tableName, []string{"name", "owner", "expires_at"}, []string{"name"}, nil)
+	if err != nil {
+		return false, fmt.Errorf("acquiring lease %q: %s", l.name, err)
+	}
+	_, err = l.conn.ExecContext(ctx, upsert, l.name, l.owner, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("acquiring lease %q: %s", l.name, err)
+	}
+
+	held, err := l.isHeld(ctx, expiresAt)
+	if err != nil {
+		return false, fmt.Errorf("acquiring lease %q: %s", l.name, err)
+	}
+	return held, nil
+}
+
+// Renew extends the lease by TTL. It returns ErrLockLost if the lease has
+// expired, even if no other owner has taken it yet.
+func (l *LeaseLock) Renew(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+	expiresAt := now.Add(l.ttl).UnixMilli()
+	_, err := l.conn.ExecContext(ctx,
+		l.conn.Rebind(fmt.Sprintf("UPDATE %s SET expires_at = ? WHERE name = ? AND owner = ? AND expires_at >= ?", l.tableName)),
+		expiresAt, l.name, l.owner, now.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("renewing lease %q: %s", l.name, err)
+	}
+
+	held, err := l.isHeld(ctx, expiresAt)
+	if err != nil {
+		return fmt.Errorf("renewing lease %q: %s", l.name, err)
+	}
+	if !held {
+		return fmt.Errorf("%w: lease %q", ErrLockLost, l.name)
+	}
+	return nil
+}
+
+func (l *LeaseLock) Release(ctx context.Context) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	_, err := l.conn.ExecContext(ctx,
+		l.conn.Rebind(fmt.Sprintf("DELETE FROM %s WHERE name = ? AND owner = ?", l.tableName)),
+		l.name, l.owner)
+	if err != nil {
+		return fmt.Errorf("releasing lease %q: %s", l.name, err)
+	}
+	return nil
+}
+
+// isHeld reports whether the lease row shows this owner with the expiry it
+// just wrote. Checking the row rather than the rows affected by the update
+// works the same for every driver, as MySQL only counts rows it changed.
+func (l *LeaseLock) isHeld(ctx context.Context, expiresAt int64) (bool, error) {
+	var lease struct {
+		Owner     string `db:"owner"`
+		ExpiresAt int64  `db:"expires_at"`
+	}
+	err := l.conn.GetContext(ctx, &lease,
+		l.conn.Rebind(fmt.Sprintf("SELECT owner, expires_at FROM %s WHERE name = ?", l.tableName)),
+		l.name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return lease.Owner == l.owner && lease.ExpiresAt == expiresAt, nil
+}
+
+func (l *LeaseLock) createTable(ctx context.Context) error {
+	if l.tableCreated {
+		return nil
+	}
+	_, err := l.conn.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name VARCHAR(255) PRIMARY KEY,
+		owner VARCHAR(255) NOT NULL,
+		expires_at BIGINT NOT NULL
+	)`, l.tableName))
+	if err != nil {
+		return fmt.Errorf("creating %s: %s", l.tableName, err)
+	}
+	l.tableCreated = true
+	return nil
+}
@@ -0,0 +1,122 @@
+package lock_test
+
+import (
+	"context"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/lock"
+	_ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LeaseLock", func() {
+	var (
+		conn       *db.ConnWrapper
+		ctx        context.Context
+		lockA      *lock.LeaseLock
+		lockB      *lock.LeaseLock
+		newLockErr error
+	)
+
+	BeforeEach(func() {
+		var err error
+		ctx = context.Background()
+		conn, err = db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		lockA, newLockErr = lock.NewLeaseLock(conn, "some-lock", "owner-a", time.Minute, "")
+		Expect(newLockErr).NotTo(HaveOccurred())
+		lockB, newLockErr = lock.NewLeaseLock(conn, "some-lock", "owner-b", time.Minute, "")
+		Expect(newLockErr).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("is held by only one owner at a time", func() {
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockB.TryAcquire(ctx)).To(BeFalse())
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+
+		Expect(lockA.Renew(ctx)).To(Succeed())
+		Expect(lockB.Renew(ctx)).To(MatchError(lock.ErrLockLost))
+	})
+
+	It("can be taken once released", func() {
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockA.Release(ctx)).To(Succeed())
+
+		Expect(lockB.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockA.Renew(ctx)).To(MatchError(lock.ErrLockLost))
+	})
+
+	It("does nothing when releasing a lock held by another owner", func() {
+		Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		Expect(lockB.Release(ctx)).To(Succeed())
+
+		Expect(lockA.Renew(ctx)).To(Succeed())
+	})
+
+	Context("when the lease expires", func() {
+		BeforeEach(func() {
+			lockA, newLockErr = lock.NewLeaseLock(conn, "some-lock", "owner-a", 50*time.Millisecond, "")
+			Expect(newLockErr).NotTo(HaveOccurred())
+		})
+
+		It("can be taken by another owner", func() {
+			Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+			Expect(lockB.TryAcquire(ctx)).To(BeFalse())
+
+			Eventually(func() (bool, error) { return lockB.TryAcquire(ctx) }).Should(BeTrue())
+			Expect(lockA.Renew(ctx)).To(MatchError(lock.ErrLockLost))
+		})
+
+		It("cannot be renewed even if no other owner has taken it", func() {
+			Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+			time.Sleep(100 * time.Millisecond)
+
+			Expect(lockA.Renew(ctx)).To(MatchError(lock.ErrLockLost))
+			Expect(lockA.TryAcquire(ctx)).To(BeTrue())
+		})
+	})
+
+	It("generates an owner when none is given", func() {
+		l, err := lock.NewLeaseLock(conn, "some-lock", "", time.Minute, "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(l.Owner()).NotTo(BeEmpty())
+	})
+
+	It("uses the given lease table", func() {
+		l, err := lock.NewLeaseLock(conn, "some-lock", "owner-c", time.Minute, "other_leases")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(l.TryAcquire(ctx)).To(BeTrue())
+
+		var owner string
+		Expect(conn.Get(&owner, "SELECT owner FROM other_leases WHERE name = ?", "some-lock")).To(Succeed())
+		Expect(owner).To(Equal("owner-c"))
+	})
+})
+
+var _ = Describe("NewAdvisoryLock", func() {
+	It("returns an error for databases without session locks", func() {
+		conn, err := db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		_, err = lock.NewAdvisoryLock(conn, "some-lock")
+		Expect(err).To(MatchError("database type 'sqlite' does not support advisory locks"))
+	})
+})
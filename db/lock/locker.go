@@ -0,0 +1,21 @@
+package lock
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrLockLost is returned by Renew when the lock is no longer held.
+var ErrLockLost = errors.New("lock lost")
+
+//go:generate counterfeiter -o fakes/locker.go --fake-name Locker . Locker
+type Locker interface {
+	// TryAcquire takes the lock if it is free, without waiting, and reports
+	// whether it is held. Calling it while the lock is held returns true.
+	TryAcquire(ctx context.Context) (bool, error)
+	// Renew confirms the lock is still held, extending it if it expires, and
+	// returns ErrLockLost if it is not.
+	Renew(ctx context.Context) error
+	// Release gives up the lock if it is held.
+	Release(ctx context.Context) error
+}
@@ -0,0 +1 @@
+package lock // import "code.cloudfoundry.org/cf-networking-helpers/db/lock"
@@ -0,0 +1,91 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Migration is a single versioned schema change. Up and Down hold the
+// statements to run for each supported database type, keyed by db.Config.Type.
+//
+// A migration's statements run in one transaction along with the ledger
+// update, so on postgres and sqlite either all of them are applied or none
+// are. MySQL commits the transaction implicitly before and after each DDL
+// statement, such as CREATE TABLE or ALTER TABLE, so if a later statement
+// fails the earlier ones stay applied and the migration is not recorded.
+// MySQL migrations should therefore hold a single DDL statement each, or
+// statements that can safely be run again.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      map[string][]string
+	Down    map[string][]string
+}
+
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+// Step is a single migration that a Migrator will run, in the direction it
+// will be run in.
+type Step struct {
+	Migration  Migration
+	Direction  string
+	Statements []string
+}
+
+func sortAndValidate(migrations []Migration, dbType string) ([]Migration, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q: version must be positive: %d", m.Name, m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d: %q and %q", m.Version, sorted[i-1].Name, m.Name)
+		}
+		if len(m.Up[dbType]) == 0 {
+			return nil, fmt.Errorf("migration %d (%s): no up statements for database type '%s'", m.Version, m.Name, dbType)
+		}
+	}
+	return sorted, nil
+}
+
+func pendingSteps(migrations []Migration, applied map[int64]bool, dbType string) []Step {
+	var steps []Step
+	for _, m := range migrations {
+		if applied[m.Version] {
+			continue
+		}
+		steps = append(steps, Step{
+			Migration:  m,
+			Direction:  DirectionUp,
+			Statements: m.Up[dbType],
+		})
+	}
+	return steps
+}
+
+func rollbackSteps(migrations []Migration, applied map[int64]bool, dbType string, count int) ([]Step, error) {
+	var steps []Step
+	for i := len(migrations) - 1; i >= 0 && len(steps) < count; i-- {
+		m := migrations[i]
+		if !applied[m.Version] {
+			continue
+		}
+		if len(m.Down[dbType]) == 0 {
+			return nil, fmt.Errorf("migration %d (%s): no down statements for database type '%s'", m.Version, m.Name, dbType)
+		}
+		steps = append(steps, Step{
+			Migration:  m,
+			Direction:  DirectionDown,
+			Statements: m.Down[dbType],
+		})
+	}
+	return steps, nil
+}
@@ -0,0 +1,287 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/lock"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DefaultTableName   = "schema_migrations"
+	DefaultLockTimeout = 60 * time.Second
+)
+
+// Migrator applies versioned migrations to the database behind Conn and
+// records them in a ledger table. The SQL run for each migration is selected
+// by the connection's driver name, which is the db.Config.Type it was opened
+// with. Only one Migrator holding the same TableName runs at a time; others
+// block on a lock.AdvisoryLock for up to LockTimeout.
+//
+// Each migration is run in its own transaction. See Migration for why MySQL
+// migrations should hold a single DDL statement.
+//
+// When DryRun is set, the plan is written to Output and nothing is changed.
+type Migrator struct {
+	Conn        *db.ConnWrapper
+	Migrations  []Migration
+	Logger      lager.Logger
+	TableName   string
+	LockTimeout time.Duration
+	DryRun      bool
+	Output      io.Writer
+}
+
+// Up applies all migrations that are not yet recorded in the ledger, in
+// version order, and returns the number applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	return m.run(ctx, func(migrations []Migration, applied map[int64]bool) ([]Step, error) {
+		return pendingSteps(migrations, applied, m.dbType()), nil
+	})
+}
+
+// Down reverts the count most recently applied migrations and returns the
+// number reverted.
+func (m *Migrator) Down(ctx context.Context, count int) (int, error) {
+	return m.run(ctx, func(migrations []Migration, applied map[int64]bool) ([]Step, error) {
+		return rollbackSteps(migrations, applied, m.dbType(), count)
+	})
+}
+
+// Plan returns the steps Up would run, without taking the lock or changing
+// anything.
+func (m *Migrator) Plan(ctx context.Context) ([]Step, error) {
+	migrations, err := sortAndValidate(m.Migrations, m.dbType())
+	if err != nil {
+		return nil, err
+	}
+	applied, err := m.appliedVersions(ctx, m.Conn)
+	if err != nil {
+		return nil, err
+	}
+	return pendingSteps(migrations, applied, m.dbType()), nil
+}
+
+func (m *Migrator) run(ctx context.Context, plan func([]Migration, map[int64]bool) ([]Step, error)) (int, error) {
+	logger := m.Logger.Session("migrate")
+
+	migrations, err := sortAndValidate(m.Migrations, m.dbType())
+	if err != nil {
+		return 0, err
+	}
+
+	if m.DryRun {
+		applied, err := m.appliedVersions(ctx, m.Conn)
+		if err != nil {
+			return 0, err
+		}
+		steps, err := plan(migrations, applied)
+		if err != nil {
+			return 0, err
+		}
+		return len(steps), m.printPlan(steps)
+	}
+
+	// The ledger and the migrations are run on the connection holding the
+	// lock, so that a pool of one connection is enough. sqlite has no
+	// advisory locks, and a database file is only migrated from a single VM;
+	// each statement still takes the database write lock.
+	var conn *sql.Conn
+	if m.dbType() == "sqlite" {
+		conn, err = m.Conn.Conn(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("getting connection: %s", err)
+		}
+		defer conn.Close()
+	} else {
+		advisoryLock, err := lock.NewAdvisoryLock(m.Conn, m.tableName())
+		if err != nil {
+			return 0, err
+		}
+		lockCtx, cancel := context.WithTimeout(ctx, m.lockTimeout())
+		err = advisoryLock.Acquire(lockCtx)
+		cancel()
+		if err != nil {
+			return 0, err
+		}
+		defer func() {
+			if err := advisoryLock.Release(context.Background()); err != nil {
+				logger.Error("release-lock", err)
+			}
+		}()
+		conn = advisoryLock.Conn()
+	}
+
+	if err := m.createLedger(ctx, conn); err != nil {
+		return 0, err
+	}
+
+	applied, err := m.appliedVersions(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	steps, err := plan(migrations, applied)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, step := range steps {
+		data := lager.Data{"version": step.Migration.Version, "name": step.Migration.Name, "direction": step.Direction}
+		logger.Info("running", data)
+		if err := m.runStep(ctx, conn, step); err != nil {
+			logger.Error("failed", err, data)
+			return i, err
+		}
+		logger.Info("done", data)
+	}
+	return len(steps), nil
+}
+
+func (m *Migrator) runStep(ctx context.Context, conn *sql.Conn, step Step) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("migration %d (%s): begin transaction: %s", step.Migration.Version, step.Migration.Name, err)
+	}
+
+	for _, statement := range step.Statements {
+		if _, err := tx.ExecContext(ctx, statement); err != nil {
+			// #nosec G104 - ignore rollback errors, prefer the error from the migration
+			tx.Rollback()
+			return fmt.Errorf("migration %d (%s) %s: %s", step.Migration.Version, step.Migration.Name, step.Direction, err)
+		}
+	}
+
+	if step.Direction == DirectionUp {
+		_, err = tx.ExecContext(ctx, m.rebind(fmt.Sprintf("INSERT INTO %s (version, name) VALUES (?, ?)", m.tableName())), step.Migration.Version, step.Migration.Name)
+	} else {
+		_, err = tx.ExecContext(ctx, m.rebind(fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.tableName())), step.Migration.Version)
+	}
+	if err != nil {
+		// #nosec G104 - ignore rollback errors, prefer the error from the ledger
+		tx.Rollback()
+		return fmt.Errorf("migration %d (%s): updating %s: %s", step.Migration.Version, step.Migration.Name, m.tableName(), err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("migration %d (%s): commit: %s", step.Migration.Version, step.Migration.Name, err)
+	}
+	return nil
+}
+
+// queryer is implemented by both *db.ConnWrapper and *sql.Conn.
+type queryer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func (m *Migrator) createLedger(ctx context.Context, q queryer) error {
+	_, err := q.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`, m.tableName()))
+	if err != nil {
+		return fmt.Errorf("creating %s: %s", m.tableName(), err)
+	}
+	return nil
+}
+
+func (m *Migrator) appliedVersions(ctx context.Context, q queryer) (map[int64]bool, error) {
+	exists, err := m.ledgerExists(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[int64]bool{}
+	if !exists {
+		return applied, nil
+	}
+
+	rows, err := q.QueryContext(ctx, fmt.Sprintf("SELECT version FROM %s", m.tableName()))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", m.tableName(), err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("reading %s: %s", m.tableName(), err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %s", m.tableName(), err)
+	}
+	return applied, nil
+}
+
+func (m *Migrator) ledgerExists(ctx context.Context, q queryer) (bool, error) {
+	var query string
+	switch m.dbType() {
+	case "postgres":
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+	case "mysql":
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case "sqlite":
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	default:
+		return false, fmt.Errorf("database type '%s' is not supported", m.dbType())
+	}
+
+	var count int
+	if err := q.QueryRowContext(ctx, query, m.tableName()).Scan(&count); err != nil {
+		return false, fmt.Errorf("checking for %s: %s", m.tableName(), err)
+	}
+	return count > 0, nil
+}
+
+func (m *Migrator) printPlan(steps []Step) error {
+	if m.Output == nil {
+		return nil
+	}
+	if len(steps) == 0 {
+		_, err := fmt.Fprintln(m.Output, "no migrations to run")
+		return err
+	}
+	for _, step := range steps {
+		if _, err := fmt.Fprintf(m.Output, "%s %d %s\n", step.Direction, step.Migration.Version, step.Migration.Name); err != nil {
+			return err
+		}
+		for _, statement := range step.Statements {
+			if _, err := fmt.Fprintf(m.Output, "    %s;\n", strings.TrimSuffix(strings.TrimSpace(statement), ";")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) dbType() string {
+	return m.Conn.DriverName()
+}
+
+func (m *Migrator) rebind(query string) string {
+	return sqlx.Rebind(sqlx.BindType(m.dbType()), query)
+}
+
+func (m *Migrator) tableName() string {
+	if m.TableName == "" {
+		return DefaultTableName
+	}
+	return m.TableName
+}
+
+func (m *Migrator) lockTimeout() time.Duration {
+	if m.LockTimeout == 0 {
+		return DefaultLockTimeout
+	}
+	return m.LockTimeout
+}
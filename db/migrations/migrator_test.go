@@ -0,0 +1,304 @@
+package migrations_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/migrations"
+	_ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Migrator", func() {
+	var (
+		dbConf   db.Config
+		database *db.ConnWrapper
+		migrator *migrations.Migrator
+		logger   *lagertest.TestLogger
+		ctx      context.Context
+		all      []migrations.Migration
+	)
+
+	both := func(statements ...string) map[string][]string {
+		return map[string][]string{"postgres": statements, "mysql": statements}
+	}
+
+	tableExists := func(name string) bool {
+		var count int
+		query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+		if database.DriverName() == "postgres" {
+			query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = $1"
+		}
+		Expect(database.Get(&count, query, name)).To(Succeed())
+		return count > 0
+	}
+
+	appliedVersions := func() []int64 {
+		var versions []int64
+		Expect(database.Select(&versions, "SELECT version FROM schema_migrations ORDER BY version")).To(Succeed())
+		return versions
+	}
+
+	BeforeEach(func() {
+		dbConf = testsupport.GetDBConfig()
+		dbConf.DatabaseName = fmt.Sprintf("test_%x", randomGenerator.Int())
+		testsupport.CreateDatabase(dbConf)
+
+		var err error
+		database, err = db.GetConnectionPool(dbConf, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		ctx = context.Background()
+		logger = lagertest.NewTestLogger("test")
+		all = []migrations.Migration{
+			{
+				Version: 2,
+				Name:    "add_gadgets",
+				Up:      both("CREATE TABLE gadgets (id INT PRIMARY KEY)"),
+				Down:    both("DROP TABLE gadgets"),
+			},
+			{
+				Version: 1,
+				Name:    "add_widgets",
+				Up:      both("CREATE TABLE widgets (id INT PRIMARY KEY)", "INSERT INTO widgets (id) VALUES (1)"),
+				Down:    both("DROP TABLE widgets"),
+			},
+		}
+		migrator = &migrations.Migrator{
+			Conn:       database,
+			Migrations: all,
+			Logger:     logger,
+		}
+	})
+
+	AfterEach(func() {
+		database.Close()
+		testsupport.RemoveDatabase(dbConf)
+	})
+
+	Describe("Up", func() {
+		It("applies pending migrations in version order and records them", func() {
+			n, err := migrator.Up(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(2))
+
+			Expect(tableExists("widgets")).To(BeTrue())
+			Expect(tableExists("gadgets")).To(BeTrue())
+			Expect(appliedVersions()).To(Equal([]int64{1, 2}))
+		})
+
+		It("only applies migrations that have not been applied yet", func() {
+			_, err := migrator.Up(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			migrator.Migrations = append(migrator.Migrations, migrations.Migration{
+				Version: 3,
+				Name:    "add_doohickeys",
+				Up:      both("CREATE TABLE doohickeys (id INT PRIMARY KEY)"),
+			})
+			n, err := migrator.Up(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(1))
+			Expect(appliedVersions()).To(Equal([]int64{1, 2, 3}))
+		})
+
+		It("selects the statements for the connection's database type", func() {
+			migrator.Migrations = []migrations.Migration{{
+				Version: 1,
+				Name:    "dialect_specific",
+				Up: map[string][]string{
+					"postgres": {"CREATE TABLE pg_only (id SERIAL PRIMARY KEY)"},
+					"mysql":    {"CREATE TABLE mysql_only (id INT AUTO_INCREMENT PRIMARY KEY)"},
+				},
+			}}
+			_, err := migrator.Up(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tableExists("pg_only")).To(Equal(database.DriverName() == "postgres"))
+			Expect(tableExists("mysql_only")).To(Equal(database.DriverName() == "mysql"))
+		})
+
+		It("runs on a pool of a single connection", func() {
+			database.SetMaxOpenConns(1)
+			timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			n, err := migrator.Up(timeoutCtx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(2))
+			Expect(appliedVersions()).To(Equal([]int64{1, 2}))
+		})
+
+		It("serializes concurrent migrators with an advisory lock", func() {
+			var wg sync.WaitGroup
+			results := make(chan int, 3)
+			for i := 0; i < 3; i++ {
+				wg.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer wg.Done()
+					m := &migrations.Migrator{Conn: database, Migrations: all, Logger: logger}
+					n, err := m.Up(ctx)
+					Expect(err).NotTo(HaveOccurred())
+					results <- n
+				}()
+			}
+			wg.Wait()
+			close(results)
+
+			total := 0
+			for n := range results {
+				total += n
+			}
+			Expect(total).To(Equal(2))
+			Expect(appliedVersions()).To(Equal([]int64{1, 2}))
+		})
+
+		Context("when a migration fails", func() {
+			It("stops, returns the error and does not record the failed migration", func() {
+				migrator.Migrations = append(migrator.Migrations, migrations.Migration{
+					Version: 3,
+					Name:    "broken",
+					Up:      both("THIS IS NOT SQL"),
+				})
+				n, err := migrator.Up(ctx)
+				Expect(err).To(MatchError(ContainSubstring("migration 3 (broken) up")))
+				Expect(n).To(Equal(2))
+				Expect(appliedVersions()).To(Equal([]int64{1, 2}))
+			})
+		})
+
+		Context("when the migrations are invalid", func() {
+			It("returns an error for duplicate versions", func() {
+				migrator.Migrations = append(migrator.Migrations, migrations.Migration{Version: 1, Name: "again", Up: both("SELECT 1")})
+				_, err := migrator.Up(ctx)
+				Expect(err).To(MatchError(ContainSubstring("duplicate migration version 1")))
+			})
+
+			It("returns an error when there is no SQL for the database type", func() {
+				migrator.Migrations = []migrations.Migration{{Version: 1, Name: "other", Up: map[string][]string{"oracle": {"SELECT 1"}}}}
+				_, err := migrator.Up(ctx)
+				Expect(err).To(MatchError(ContainSubstring("no up statements for database type")))
+			})
+		})
+
+		Context("when DryRun is set", func() {
+			It("prints the plan without changing anything", func() {
+				output := &bytes.Buffer{}
+				migrator.DryRun = true
+				migrator.Output = output
+
+				n, err := migrator.Up(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(n).To(Equal(2))
+
+				Expect(output.String()).To(Equal("up 1 add_widgets\n" +
+					"    CREATE TABLE widgets (id INT PRIMARY KEY);\n" +
+					"    INSERT INTO widgets (id) VALUES (1);\n" +
+					"up 2 add_gadgets\n" +
+					"    CREATE TABLE gadgets (id INT PRIMARY KEY);\n"))
+				Expect(tableExists("schema_migrations")).To(BeFalse())
+				Expect(tableExists("widgets")).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("Down", func() {
+		BeforeEach(func() {
+			_, err := migrator.Up(ctx)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reverts the most recent migrations", func() {
+			n, err := migrator.Down(ctx, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(n).To(Equal(1))
+
+			Expect(tableExists("gadgets")).To(BeFalse())
+			Expect(tableExists("widgets")).To(BeTrue())
+			Expect(appliedVersions()).To(Equal([]int64{1}))
+		})
+
+		It("returns an error when a migration has no down statements", func() {
+			migrator.Migrations[0].Down = nil
+			_, err := migrator.Down(ctx, 2)
+			Expect(err).To(MatchError(ContainSubstring("no down statements")))
+		})
+	})
+
+	Describe("Plan", func() {
+		It("returns the pending steps without creating the ledger", func() {
+			steps, err := migrator.Plan(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(steps).To(HaveLen(2))
+			Expect(steps[0].Migration.Name).To(Equal("add_widgets"))
+			Expect(steps[0].Direction).To(Equal(migrations.DirectionUp))
+			Expect(tableExists("schema_migrations")).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("Migrator on sqlite", func() {
+	var (
+		database *db.ConnWrapper
+		migrator *migrations.Migrator
+		ctx      context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		database, err = db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		database.SetMaxOpenConns(1)
+
+		ctx = context.Background()
+		migrator = &migrations.Migrator{
+			Conn: database,
+			Migrations: []migrations.Migration{{
+				Version: 1,
+				Name:    "add_widgets",
+				Up:      map[string][]string{"sqlite": {"CREATE TABLE widgets (id INTEGER PRIMARY KEY)"}},
+				Down:    map[string][]string{"sqlite": {"DROP TABLE widgets"}},
+			}},
+			Logger: lagertest.NewTestLogger("test"),
+		}
+	})
+
+	AfterEach(func() {
+		Expect(database.Close()).To(Succeed())
+	})
+
+	It("applies and reverts migrations on a pool of a single connection", func() {
+		timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		n, err := migrator.Up(timeoutCtx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+
+		var versions []int64
+		Expect(database.Select(&versions, "SELECT version FROM schema_migrations")).To(Succeed())
+		Expect(versions).To(Equal([]int64{1}))
+
+		n, err = migrator.Down(timeoutCtx, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+
+		var count int
+		Expect(database.Get(&count, "SELECT COUNT(*) FROM sqlite_master WHERE name = 'widgets'")).To(Succeed())
+		Expect(count).To(Equal(0))
+	})
+})
@@ -0,0 +1 @@
+package migrations // import "code.cloudfoundry.org/cf-networking-helpers/db/migrations"
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	histogramBuckets            = 80
+	histogramMinBound           = 100 * time.Microsecond
+	histogramBucketsPerDoubling = 4
+)
+
+// bucketBounds are the inclusive upper bounds of each histogram bucket. They
+// grow by a factor of 2^(1/4) starting at 100µs, so every bucket is within
+// ~19% of its neighbours and the last bound is roughly 90s. Durations above
+// the last bound land in an overflow bucket.
+var bucketBounds = func() [histogramBuckets]time.Duration {
+	var bounds [histogramBuckets]time.Duration
+	for i := range bounds {
+		factor := math.Pow(2, float64(i)/histogramBucketsPerDoubling)
+		bounds[i] = time.Duration(float64(histogramMinBound) * factor)
+	}
+	return bounds
+}()
+
+// DurationHistogram is a log-bucketed histogram of query durations.
+type DurationHistogram struct {
+	counts [histogramBuckets + 1]int64
+	total  int64
+	max    time.Duration
+}
+
+func (h *DurationHistogram) Record(d time.Duration) {
+	h.counts[bucketFor(d)]++
+	h.total++
+	if d > h.max {
+		h.max = d
+	}
+}
+
+func (h *DurationHistogram) Count() int64 {
+	return h.total
+}
+
+// Percentile returns an upper bound for the duration below which p percent
+// of the recorded durations fall. It is accurate to the width of a bucket and
+// never exceeds the largest recorded duration. It returns 0 if nothing has
+// been recorded.
+func (h *DurationHistogram) Percentile(p float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := int64(math.Ceil(p / 100 * float64(h.total)))
+	if rank < 1 {
+		rank = 1
+	}
+
+	var seen int64
+	for i, count := range h.counts {
+		seen += count
+		if seen < rank {
+			continue
+		}
+		if i == histogramBuckets || bucketBounds[i] > h.max {
+			return h.max
+		}
+		return bucketBounds[i]
+	}
+	return h.max
+}
+
+func bucketFor(d time.Duration) int {
+	if d <= histogramMinBound {
+		return 0
+	}
+	i := int(math.Ceil(math.Log2(float64(d)/float64(histogramMinBound)) * histogramBucketsPerDoubling))
+	if i > histogramBuckets {
+		i = histogramBuckets
+	}
+	// correct for floating point error at the bucket edges
+	for i < histogramBuckets && d > bucketBounds[i] {
+		i++
+	}
+	for i > 0 && d <= bucketBounds[i-1] {
+		i--
+	}
+	return i
+}
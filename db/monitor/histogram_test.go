@@ -0,0 +1,64 @@
+package monitor_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DurationHistogram", func() {
+	var histogram *monitor.DurationHistogram
+
+	BeforeEach(func() {
+		histogram = &monitor.DurationHistogram{}
+	})
+
+	It("returns zero when nothing has been recorded", func() {
+		Expect(histogram.Count()).To(BeZero())
+		Expect(histogram.Percentile(50)).To(BeZero())
+		Expect(histogram.Percentile(99)).To(BeZero())
+	})
+
+	It("counts recorded durations", func() {
+		histogram.Record(time.Millisecond)
+		histogram.Record(time.Second)
+		Expect(histogram.Count()).To(BeEquivalentTo(2))
+	})
+
+	It("approximates percentiles to within a bucket width", func() {
+		for i := 1; i <= 100; i++ {
+			histogram.Record(time.Duration(i) * time.Millisecond)
+		}
+
+		Expect(histogram.Percentile(50)).To(BeNumerically("~", 50*time.Millisecond, 10*time.Millisecond))
+		Expect(histogram.Percentile(90)).To(BeNumerically("~", 90*time.Millisecond, 18*time.Millisecond))
+		Expect(histogram.Percentile(99)).To(BeNumerically("~", 99*time.Millisecond, 2*time.Millisecond))
+		Expect(histogram.Percentile(100)).To(Equal(100 * time.Millisecond))
+	})
+
+	It("exposes the tail separately from the median", func() {
+		for i := 0; i < 98; i++ {
+			histogram.Record(2 * time.Millisecond)
+		}
+		histogram.Record(3 * time.Second)
+		histogram.Record(3 * time.Second)
+
+		Expect(histogram.Percentile(50)).To(BeNumerically("<=", 3*time.Millisecond))
+		Expect(histogram.Percentile(99)).To(BeNumerically(">=", 2*time.Second))
+	})
+
+	It("never reports more than the largest recorded duration", func() {
+		histogram.Record(101 * time.Microsecond)
+		Expect(histogram.Percentile(99)).To(Equal(101 * time.Microsecond))
+	})
+
+	It("handles durations outside the bucket range", func() {
+		histogram.Record(time.Nanosecond)
+		histogram.Record(10 * time.Minute)
+
+		Expect(histogram.Percentile(50)).To(Equal(100 * time.Microsecond))
+		Expect(histogram.Percentile(100)).To(Equal(10 * time.Minute))
+	})
+})
@@ -1,7 +1,9 @@
 package monitor
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,51 +14,93 @@ import (
 //counterfeiter:generate . Monitor
 type Monitor interface {
 	Monitor(func() error) error
+	MonitorNamed(string, func() error) error
 
 	Total() int64
 	Succeeded() int64
 	Failed() int64
+	TimedOut() int64
+	RecordRetry()
+	Retries() int64
+	RecordConnectRetry()
+	ConnectRetries() int64
+	RecordSlowQuery()
+	SlowQueries() int64
 
 	ReadAndResetDurationMax() time.Duration
+	ReadAndResetDurationHistogram() DurationHistogram
 	ReadAndResetInFlightMax() int64
+	ReadAndResetQueryStats() map[string]QueryStats
 }
 
 type monitor struct {
-	total     int64
-	succeeded int64
-	failed    int64
+	total          int64
+	succeeded      int64
+	failed         int64
+	timedOut       int64
+	retries        int64
+	connectRetries int64
+	slowQueries    int64
 
 	durationLock *sync.RWMutex
 	durationMax  time.Duration
+	durations    DurationHistogram
 
 	inFlightLock *sync.RWMutex
 	inFlight     int64
 	inFlightMax  int64
+
+	queryStats *queryStats
 }
 
 func New() Monitor {
+	return NewWithMaxQueryNames(DefaultMaxQueryNames)
+}
+
+// NewWithMaxQueryNames returns a Monitor that tracks at most maxQueryNames
+// distinct query names. Queries with names beyond the cap are tracked under
+// OverflowQueryName.
+func NewWithMaxQueryNames(maxQueryNames int) Monitor {
 	return &monitor{
 		durationLock: new(sync.RWMutex),
 		inFlightLock: new(sync.RWMutex),
+		queryStats:   newQueryStats(maxQueryNames),
 	}
 }
 
 func (m *monitor) Monitor(f func() error) error {
+	return m.MonitorNamed("", f)
+}
+
+// MonitorNamed monitors f like Monitor, and additionally tracks it under the
+// given query name unless the name is empty.
+func (m *monitor) MonitorNamed(name string, f func() error) error {
 	m.updateInFlight(1)
 	defer m.updateInFlight(-1)
 
 	start := time.Now()
 	err := f()
-	m.setDurationMax(time.Since(start))
-
-	if err != nil && err != sql.ErrNoRows {
-		if err != sql.ErrTxDone {
-			atomic.AddInt64(&m.total, 1)
-			atomic.AddInt64(&m.failed, 1)
-		}
-	} else {
+	duration := time.Since(start)
+	m.recordDuration(duration)
+
+	var result outcome
+	switch {
+	case err == nil || err == sql.ErrNoRows:
 		atomic.AddInt64(&m.total, 1)
 		atomic.AddInt64(&m.succeeded, 1)
+		result = succeeded
+	case errors.Is(err, context.DeadlineExceeded):
+		atomic.AddInt64(&m.total, 1)
+		atomic.AddInt64(&m.timedOut, 1)
+		result = timedOut
+	case err != sql.ErrTxDone:
+		atomic.AddInt64(&m.total, 1)
+		atomic.AddInt64(&m.failed, 1)
+		result = failed
+	}
+
+	if name != "" && result != notCounted {
+		m.queryStats.record(name, result, duration)
 	}
 
 	return err
@@ -74,6 +118,38 @@ func (m *monitor) Failed() int64 {
 	return atomic.LoadInt64(&m.failed)
 }
 
+// TimedOut returns the number of queries that ran past their context's
+// deadline. They are not counted as failed.
+func (m *monitor) TimedOut() int64 {
+	return atomic.LoadInt64(&m.timedOut)
+}
+
+func (m *monitor) RecordRetry() {
+	atomic.AddInt64(&m.retries, 1)
+}
+
+func (m *monitor) Retries() int64 {
+	return atomic.LoadInt64(&m.retries)
+}
+
+// RecordConnectRetry counts a retry made while connecting the pool, as
+// opposed to a transaction retried by RecordRetry.
+func (m *monitor) RecordConnectRetry() {
+	atomic.AddInt64(&m.connectRetries, 1)
+}
+
+func (m *monitor) ConnectRetries() int64 {
+	return atomic.LoadInt64(&m.connectRetries)
+}
+
+func (m *monitor) RecordSlowQuery() {
+	atomic.AddInt64(&m.slowQueries, 1)
+}
+
+func (m *monitor) SlowQueries() int64 {
+	return atomic.LoadInt64(&m.slowQueries)
+}
+
 func (m *monitor) ReadAndResetInFlightMax() int64 {
 	var oldMax int64
 	m.inFlightLock.Lock()
@@ -101,10 +177,23 @@ func (m *monitor) ReadAndResetDurationMax() time.Duration {
 	return oldDuration
 }
 
-func (m *monitor) setDurationMax(d time.Duration) {
+func (m *monitor) ReadAndResetDurationHistogram() DurationHistogram {
+	m.durationLock.Lock()
+	histogram := m.durations
+	m.durations = DurationHistogram{}
+	m.durationLock.Unlock()
+	return histogram
+}
+
+func (m *monitor) recordDuration(d time.Duration) {
 	m.durationLock.Lock()
 	if d > m.durationMax {
 		m.durationMax = d
 	}
+	m.durations.Record(d)
 	m.durationLock.Unlock()
 }
+
+func (m *monitor) ReadAndResetQueryStats() map[string]QueryStats {
+	return m.queryStats.readAndReset()
+}
@@ -1,8 +1,10 @@
 package monitor_test
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
@@ -71,6 +73,20 @@ var _ = Describe("Monitor", func() {
 			})
 		})
 
+		Context("when a statement runs past its deadline", func() {
+			It("increments the total and timed out counts by 1 and returns the error", func() {
+				err := mon.Monitor(func() error {
+					return fmt.Errorf("pq: canceling statement due to user request: %w", context.DeadlineExceeded)
+				})
+				Expect(err).To(MatchError(context.DeadlineExceeded))
+
+				Expect(mon.Total()).To(BeEquivalentTo(1))
+				Expect(mon.Succeeded()).To(BeEquivalentTo(0))
+				Expect(mon.Failed()).To(BeEquivalentTo(0))
+				Expect(mon.TimedOut()).To(BeEquivalentTo(1))
+			})
+		})
+
 		It("records the max number of queries in flight", func() {
 			cleanupCh := make(chan struct{})
 			defer close(cleanupCh)
@@ -141,6 +157,88 @@ var _ = Describe("Monitor", func() {
 		})
 	})
 
+	Describe("#MonitorNamed", func() {
+		It("counts the query in the overall totals", func() {
+			err := mon.MonitorNamed("select-policies", func() error {
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mon.Total()).To(BeEquivalentTo(1))
+			Expect(mon.Succeeded()).To(BeEquivalentTo(1))
+		})
+
+		It("tracks total, succeeded, failed, timed out and max duration per name", func() {
+			mon.MonitorNamed("select-policies", func() error {
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+			mon.MonitorNamed("select-policies", func() error {
+				return errors.New("boom!")
+			})
+			mon.MonitorNamed("select-policies", func() error {
+				return context.DeadlineExceeded
+			})
+			mon.MonitorNamed("delete-policies", func() error {
+				return sql.ErrNoRows
+			})
+			mon.MonitorNamed("delete-policies", func() error {
+				return sql.ErrTxDone
+			})
+			mon.Monitor(func() error {
+				return nil
+			})
+
+			stats := mon.ReadAndResetQueryStats()
+			Expect(stats).To(HaveLen(2))
+			Expect(stats["select-policies"].Total).To(BeEquivalentTo(3))
+			Expect(stats["select-policies"].Succeeded).To(BeEquivalentTo(1))
+			Expect(stats["select-policies"].Failed).To(BeEquivalentTo(1))
+			Expect(stats["select-policies"].TimedOut).To(BeEquivalentTo(1))
+			Expect(stats["select-policies"].DurationMax).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(stats["delete-policies"].Total).To(BeEquivalentTo(1))
+			Expect(stats["delete-policies"].Succeeded).To(BeEquivalentTo(1))
+		})
+
+		It("keeps counts but resets the max duration on read", func() {
+			mon.MonitorNamed("select-policies", func() error {
+				return nil
+			})
+			mon.ReadAndResetQueryStats()
+
+			stats := mon.ReadAndResetQueryStats()
+			Expect(stats["select-policies"].Total).To(BeEquivalentTo(1))
+			Expect(stats["select-policies"].DurationMax).To(BeZero())
+		})
+
+		Context("when the number of names exceeds the cap", func() {
+			BeforeEach(func() {
+				mon = monitor.NewWithMaxQueryNames(2)
+			})
+
+			It("tracks additional names under the overflow name", func() {
+				for _, name := range []string{"a", "b", "c", "d", "a"} {
+					mon.MonitorNamed(name, func() error {
+						return nil
+					})
+				}
+
+				stats := mon.ReadAndResetQueryStats()
+				Expect(stats).To(HaveLen(3))
+				Expect(stats["a"].Total).To(BeEquivalentTo(2))
+				Expect(stats["b"].Total).To(BeEquivalentTo(1))
+				Expect(stats[monitor.OverflowQueryName].Total).To(BeEquivalentTo(2))
+			})
+		})
+	})
+
+	Describe("query name context", func() {
+		It("round trips the query name through the context", func() {
+			ctx := monitor.WithQueryName(context.Background(), "select-policies")
+			Expect(monitor.QueryName(ctx)).To(Equal("select-policies"))
+			Expect(monitor.QueryName(context.Background())).To(BeEmpty())
+		})
+	})
+
 	Describe("#Total", func() {
 		It("returns the total number of queries ran", func() {
 			mon.Monitor(func() error {
@@ -186,6 +284,35 @@ var _ = Describe("Monitor", func() {
 		})
 	})
 
+	Describe("#Retries", func() {
+		It("returns the number of retries recorded", func() {
+			mon.RecordRetry()
+			mon.RecordRetry()
+
+			Expect(mon.Retries()).To(BeEquivalentTo(2))
+			Expect(mon.Total()).To(BeEquivalentTo(0))
+		})
+	})
+
+	Describe("#ConnectRetries", func() {
+		It("returns the number of connect retries recorded, apart from transaction retries", func() {
+			mon.RecordConnectRetry()
+			mon.RecordRetry()
+
+			Expect(mon.ConnectRetries()).To(BeEquivalentTo(1))
+			Expect(mon.Retries()).To(BeEquivalentTo(1))
+		})
+	})
+
+	Describe("#SlowQueries", func() {
+		It("returns the number of slow queries recorded", func() {
+			mon.RecordSlowQuery()
+
+			Expect(mon.SlowQueries()).To(BeEquivalentTo(1))
+			Expect(mon.Total()).To(BeEquivalentTo(0))
+		})
+	})
+
 	Describe("#ReadAndResetInFlightMax", func() {
 		It("resets the max number of queries in flight to the current number of queries in flight", func() {
 			blockCh1 := make(chan struct{})
@@ -227,6 +354,28 @@ var _ = Describe("Monitor", func() {
 		})
 	})
 
+	Describe("#ReadAndResetDurationHistogram", func() {
+		It("returns the durations of all queries ran since last reset and resets", func() {
+			mon.Monitor(func() error {
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+			mon.Monitor(func() error {
+				time.Sleep(100 * time.Millisecond)
+				return nil
+			})
+
+			histogram := mon.ReadAndResetDurationHistogram()
+			Expect(histogram.Count()).To(BeEquivalentTo(2))
+			Expect(histogram.Percentile(50)).To(BeNumerically(">=", 10*time.Millisecond))
+			Expect(histogram.Percentile(50)).To(BeNumerically("<", 50*time.Millisecond))
+			Expect(histogram.Percentile(99)).To(BeNumerically(">=", 100*time.Millisecond))
+
+			histogram = mon.ReadAndResetDurationHistogram()
+			Expect(histogram.Count()).To(BeZero())
+		})
+	})
+
 	Describe("#ReadAndResetDurationMax", func() {
 		It("resets the max duration of all queries ran since last reset to 0", func() {
 			mon.Monitor(func() error {
@@ -9,6 +9,16 @@ import (
 )
 
 type FakeMonitor struct {
+	ConnectRetriesStub        func() int64
+	connectRetriesMutex       sync.RWMutex
+	connectRetriesArgsForCall []struct {
+	}
+	connectRetriesReturns struct {
+		result1 int64
+	}
+	connectRetriesReturnsOnCall map[int]struct {
+		result1 int64
+	}
 	FailedStub        func() int64
 	failedMutex       sync.RWMutex
 	failedArgsForCall []struct {
@@ -30,6 +40,28 @@ type FakeMonitor struct {
 	monitorReturnsOnCall map[int]struct {
 		result1 error
 	}
+	MonitorNamedStub        func(string, func() error) error
+	monitorNamedMutex       sync.RWMutex
+	monitorNamedArgsForCall []struct {
+		arg1 string
+		arg2 func() error
+	}
+	monitorNamedReturns struct {
+		result1 error
+	}
+	monitorNamedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReadAndResetDurationHistogramStub        func() monitor.DurationHistogram
+	readAndResetDurationHistogramMutex       sync.RWMutex
+	readAndResetDurationHistogramArgsForCall []struct {
+	}
+	readAndResetDurationHistogramReturns struct {
+		result1 monitor.DurationHistogram
+	}
+	readAndResetDurationHistogramReturnsOnCall map[int]struct {
+		result1 monitor.DurationHistogram
+	}
 	ReadAndResetDurationMaxStub        func() time.Duration
 	readAndResetDurationMaxMutex       sync.RWMutex
 	readAndResetDurationMaxArgsForCall []struct {
@@ -50,6 +82,48 @@ type FakeMonitor struct {
 	readAndResetInFlightMaxReturnsOnCall map[int]struct {
 		result1 int64
 	}
+	ReadAndResetQueryStatsStub        func() map[string]monitor.QueryStats
+	readAndResetQueryStatsMutex       sync.RWMutex
+	readAndResetQueryStatsArgsForCall []struct {
+	}
+	readAndResetQueryStatsReturns struct {
+		result1 map[string]monitor.QueryStats
+	}
+	readAndResetQueryStatsReturnsOnCall map[int]struct {
+		result1 map[string]monitor.QueryStats
+	}
+	RecordConnectRetryStub        func()
+	recordConnectRetryMutex       sync.RWMutex
+	recordConnectRetryArgsForCall []struct {
+	}
+	RecordRetryStub        func()
+	recordRetryMutex       sync.RWMutex
+	recordRetryArgsForCall []struct {
+	}
+	RecordSlowQueryStub        func()
+	recordSlowQueryMutex       sync.RWMutex
+	recordSlowQueryArgsForCall []struct {
+	}
+	RetriesStub        func() int64
+	retriesMutex       sync.RWMutex
+	retriesArgsForCall []struct {
+	}
+	retriesReturns struct {
+		result1 int64
+	}
+	retriesReturnsOnCall map[int]struct {
+		result1 int64
+	}
+	SlowQueriesStub        func() int64
+	slowQueriesMutex       sync.RWMutex
+	slowQueriesArgsForCall []struct {
+	}
+	slowQueriesReturns struct {
+		result1 int64
+	}
+	slowQueriesReturnsOnCall map[int]struct {
+		result1 int64
+	}
 	SucceededStub        func() int64
 	succeededMutex       sync.RWMutex
 	succeededArgsForCall []struct {
@@ -60,6 +134,16 @@ type FakeMonitor struct {
 	succeededReturnsOnCall map[int]struct {
 		result1 int64
 	}
+	TimedOutStub        func() int64
+	timedOutMutex       sync.RWMutex
+	timedOutArgsForCall []struct {
+	}
+	timedOutReturns struct {
+		result1 int64
+	}
+	timedOutReturnsOnCall map[int]struct {
+		result1 int64
+	}
 	TotalStub        func() int64
 	totalMutex       sync.RWMutex
 	totalArgsForCall []struct {
@@ -74,6 +158,59 @@ type FakeMonitor struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeMonitor) ConnectRetries() int64 {
+	fake.connectRetriesMutex.Lock()
+	ret, specificReturn := fake.connectRetriesReturnsOnCall[len(fake.connectRetriesArgsForCall)]
+	fake.connectRetriesArgsForCall = append(fake.connectRetriesArgsForCall, struct {
+	}{})
+	stub := fake.ConnectRetriesStub
+	fakeReturns := fake.connectRetriesReturns
+	fake.recordInvocation("ConnectRetries", []interface{}{})
+	fake.connectRetriesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) ConnectRetriesCallCount() int {
+	fake.connectRetriesMutex.RLock()
+	defer fake.connectRetriesMutex.RUnlock()
+	return len(fake.connectRetriesArgsForCall)
+}
+
+func (fake *FakeMonitor) ConnectRetriesCalls(stub func() int64) {
+	fake.connectRetriesMutex.Lock()
+	defer fake.connectRetriesMutex.Unlock()
+	fake.ConnectRetriesStub = stub
+}
+
+func (fake *FakeMonitor) ConnectRetriesReturns(result1 int64) {
+	fake.connectRetriesMutex.Lock()
+	defer fake.connectRetriesMutex.Unlock()
+	fake.ConnectRetriesStub = nil
+	fake.connectRetriesReturns = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *FakeMonitor) ConnectRetriesReturnsOnCall(i int, result1 int64) {
+	fake.connectRetriesMutex.Lock()
+	defer fake.connectRetriesMutex.Unlock()
+	fake.ConnectRetriesStub = nil
+	if fake.connectRetriesReturnsOnCall == nil {
+		fake.connectRetriesReturnsOnCall = make(map[int]struct {
+			result1 int64
+		})
+	}
+	fake.connectRetriesReturnsOnCall[i] = struct {
+		result1 int64
+	}{result1}
+}
+
 func (fake *FakeMonitor) Failed() int64 {
 	fake.failedMutex.Lock()
 	ret, specificReturn := fake.failedReturnsOnCall[len(fake.failedArgsForCall)]
@@ -188,6 +325,121 @@ func (fake *FakeMonitor) MonitorReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeMonitor) MonitorNamed(arg1 string, arg2 func() error) error {
+	fake.monitorNamedMutex.Lock()
+	ret, specificReturn := fake.monitorNamedReturnsOnCall[len(fake.monitorNamedArgsForCall)]
+	fake.monitorNamedArgsForCall = append(fake.monitorNamedArgsForCall, struct {
+		arg1 string
+		arg2 func() error
+	}{arg1, arg2})
+	stub := fake.MonitorNamedStub
+	fakeReturns := fake.monitorNamedReturns
+	fake.recordInvocation("MonitorNamed", []interface{}{arg1, arg2})
+	fake.monitorNamedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) MonitorNamedCallCount() int {
+	fake.monitorNamedMutex.RLock()
+	defer fake.monitorNamedMutex.RUnlock()
+	return len(fake.monitorNamedArgsForCall)
+}
+
+func (fake *FakeMonitor) MonitorNamedCalls(stub func(string, func() error) error) {
+	fake.monitorNamedMutex.Lock()
+	defer fake.monitorNamedMutex.Unlock()
+	fake.MonitorNamedStub = stub
+}
+
+func (fake *FakeMonitor) MonitorNamedArgsForCall(i int) (string, func() error) {
+	fake.monitorNamedMutex.RLock()
+	defer fake.monitorNamedMutex.RUnlock()
+	argsForCall := fake.monitorNamedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeMonitor) MonitorNamedReturns(result1 error) {
+	fake.monitorNamedMutex.Lock()
+	defer fake.monitorNamedMutex.Unlock()
+	fake.MonitorNamedStub = nil
+	fake.monitorNamedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMonitor) MonitorNamedReturnsOnCall(i int, result1 error) {
+	fake.monitorNamedMutex.Lock()
+	defer fake.monitorNamedMutex.Unlock()
+	fake.MonitorNamedStub = nil
+	if fake.monitorNamedReturnsOnCall == nil {
+		fake.monitorNamedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.monitorNamedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeMonitor) ReadAndResetDurationHistogram() monitor.DurationHistogram {
+	fake.readAndResetDurationHistogramMutex.Lock()
+	ret, specificReturn := fake.readAndResetDurationHistogramReturnsOnCall[len(fake.readAndResetDurationHistogramArgsForCall)]
+	fake.readAndResetDurationHistogramArgsForCall = append(fake.readAndResetDurationHistogramArgsForCall, struct {
+	}{})
+	stub := fake.ReadAndResetDurationHistogramStub
+	fakeReturns := fake.readAndResetDurationHistogramReturns
+	fake.recordInvocation("ReadAndResetDurationHistogram", []interface{}{})
+	fake.readAndResetDurationHistogramMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) ReadAndResetDurationHistogramCallCount() int {
+	fake.readAndResetDurationHistogramMutex.RLock()
+	defer fake.readAndResetDurationHistogramMutex.RUnlock()
+	return len(fake.readAndResetDurationHistogramArgsForCall)
+}
+
+func (fake *FakeMonitor) ReadAndResetDurationHistogramCalls(stub func() monitor.DurationHistogram) {
+	fake.readAndResetDurationHistogramMutex.Lock()
+	defer fake.readAndResetDurationHistogramMutex.Unlock()
+	fake.ReadAndResetDurationHistogramStub = stub
+}
+
+func (fake *FakeMonitor) ReadAndResetDurationHistogramReturns(result1 monitor.DurationHistogram) {
+	fake.readAndResetDurationHistogramMutex.Lock()
+	defer fake.readAndResetDurationHistogramMutex.Unlock()
+	fake.ReadAndResetDurationHistogramStub = nil
+	fake.readAndResetDurationHistogramReturns = struct {
+		result1 monitor.DurationHistogram
+	}{result1}
+}
+
+func (fake *FakeMonitor) ReadAndResetDurationHistogramReturnsOnCall(i int, result1 monitor.DurationHistogram) {
+	fake.readAndResetDurationHistogramMutex.Lock()
+	defer fake.readAndResetDurationHistogramMutex.Unlock()
+	fake.ReadAndResetDurationHistogramStub = nil
+	if fake.readAndResetDurationHistogramReturnsOnCall == nil {
+		fake.readAndResetDurationHistogramReturnsOnCall = make(map[int]struct {
+			result1 monitor.DurationHistogram
+		})
+	}
+	fake.readAndResetDurationHistogramReturnsOnCall[i] = struct {
+		result1 monitor.DurationHistogram
+	}{result1}
+}
+
 func (fake *FakeMonitor) ReadAndResetDurationMax() time.Duration {
 	fake.readAndResetDurationMaxMutex.Lock()
 	ret, specificReturn := fake.readAndResetDurationMaxReturnsOnCall[len(fake.readAndResetDurationMaxArgsForCall)]
@@ -294,6 +546,237 @@ func (fake *FakeMonitor) ReadAndResetInFlightMaxReturnsOnCall(i int, result1 int
 	}{result1}
 }
 
+func (fake *FakeMonitor) ReadAndResetQueryStats() map[string]monitor.QueryStats {
+	fake.readAndResetQueryStatsMutex.Lock()
+	ret, specificReturn := fake.readAndResetQueryStatsReturnsOnCall[len(fake.readAndResetQueryStatsArgsForCall)]
+	fake.readAndResetQueryStatsArgsForCall = append(fake.readAndResetQueryStatsArgsForCall, struct {
+	}{})
+	stub := fake.ReadAndResetQueryStatsStub
+	fakeReturns := fake.readAndResetQueryStatsReturns
+	fake.recordInvocation("ReadAndResetQueryStats", []interface{}{})
+	fake.readAndResetQueryStatsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) ReadAndResetQueryStatsCallCount() int {
+	fake.readAndResetQueryStatsMutex.RLock()
+	defer fake.readAndResetQueryStatsMutex.RUnlock()
+	return len(fake.readAndResetQueryStatsArgsForCall)
+}
+
+func (fake *FakeMonitor) ReadAndResetQueryStatsCalls(stub func() map[string]monitor.QueryStats) {
+	fake.readAndResetQueryStatsMutex.Lock()
+	defer fake.readAndResetQueryStatsMutex.Unlock()
+	fake.ReadAndResetQueryStatsStub = stub
+}
+
+func (fake *FakeMonitor) ReadAndResetQueryStatsReturns(result1 map[string]monitor.QueryStats) {
+	fake.readAndResetQueryStatsMutex.Lock()
+	defer fake.readAndResetQueryStatsMutex.Unlock()
+	fake.ReadAndResetQueryStatsStub = nil
+	fake.readAndResetQueryStatsReturns = struct {
+		result1 map[string]monitor.QueryStats
+	}{result1}
+}
+
+func (fake *FakeMonitor) ReadAndResetQueryStatsReturnsOnCall(i int, result1 map[string]monitor.QueryStats) {
+	fake.readAndResetQueryStatsMutex.Lock()
+	defer fake.readAndResetQueryStatsMutex.Unlock()
+	fake.ReadAndResetQueryStatsStub = nil
+	if fake.readAndResetQueryStatsReturnsOnCall == nil {
+		fake.readAndResetQueryStatsReturnsOnCall = make(map[int]struct {
+			result1 map[string]monitor.QueryStats
+		})
+	}
+	fake.readAndResetQueryStatsReturnsOnCall[i] = struct {
+		result1 map[string]monitor.QueryStats
+	}{result1}
+}
+
+func (fake *FakeMonitor) RecordConnectRetry() {
+	fake.recordConnectRetryMutex.Lock()
+	fake.recordConnectRetryArgsForCall = append(fake.recordConnectRetryArgsForCall, struct {
+	}{})
+	stub := fake.RecordConnectRetryStub
+	fake.recordInvocation("RecordConnectRetry", []interface{}{})
+	fake.recordConnectRetryMutex.Unlock()
+	if stub != nil {
+		fake.RecordConnectRetryStub()
+	}
+}
+
+func (fake *FakeMonitor) RecordConnectRetryCallCount() int {
+	fake.recordConnectRetryMutex.RLock()
+	defer fake.recordConnectRetryMutex.RUnlock()
+	return len(fake.recordConnectRetryArgsForCall)
+}
+
+func (fake *FakeMonitor) RecordConnectRetryCalls(stub func()) {
+	fake.recordConnectRetryMutex.Lock()
+	defer fake.recordConnectRetryMutex.Unlock()
+	fake.RecordConnectRetryStub = stub
+}
+
+func (fake *FakeMonitor) RecordRetry() {
+	fake.recordRetryMutex.Lock()
+	fake.recordRetryArgsForCall = append(fake.recordRetryArgsForCall, struct {
+	}{})
+	stub := fake.RecordRetryStub
+	fake.recordInvocation("RecordRetry", []interface{}{})
+	fake.recordRetryMutex.Unlock()
+	if stub != nil {
+		fake.RecordRetryStub()
+	}
+}
+
+func (fake *FakeMonitor) RecordRetryCallCount() int {
+	fake.recordRetryMutex.RLock()
+	defer fake.recordRetryMutex.RUnlock()
+	return len(fake.recordRetryArgsForCall)
+}
+
+func (fake *FakeMonitor) RecordRetryCalls(stub func()) {
+	fake.recordRetryMutex.Lock()
+	defer fake.recordRetryMutex.Unlock()
+	fake.RecordRetryStub = stub
+}
+
+func (fake *FakeMonitor) RecordSlowQuery() {
+	fake.recordSlowQueryMutex.Lock()
+	fake.recordSlowQueryArgsForCall = append(fake.recordSlowQueryArgsForCall, struct {
+	}{})
+	stub := fake.RecordSlowQueryStub
+	fake.recordInvocation("RecordSlowQuery", []interface{}{})
+	fake.recordSlowQueryMutex.Unlock()
+	if stub != nil {
+		fake.RecordSlowQueryStub()
+	}
+}
+
+func (fake *FakeMonitor) RecordSlowQueryCallCount() int {
+	fake.recordSlowQueryMutex.RLock()
+	defer fake.recordSlowQueryMutex.RUnlock()
+	return len(fake.recordSlowQueryArgsForCall)
+}
+
+func (fake *FakeMonitor) RecordSlowQueryCalls(stub func()) {
+	fake.recordSlowQueryMutex.Lock()
+	defer fake.recordSlowQueryMutex.Unlock()
+	fake.RecordSlowQueryStub = stub
+}
+
+func (fake *FakeMonitor) Retries() int64 {
+	fake.retriesMutex.Lock()
+	ret, specificReturn := fake.retriesReturnsOnCall[len(fake.retriesArgsForCall)]
+	fake.retriesArgsForCall = append(fake.retriesArgsForCall, struct {
+	}{})
+	stub := fake.RetriesStub
+	fakeReturns := fake.retriesReturns
+	fake.recordInvocation("Retries", []interface{}{})
+	fake.retriesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) RetriesCallCount() int {
+	fake.retriesMutex.RLock()
+	defer fake.retriesMutex.RUnlock()
+	return len(fake.retriesArgsForCall)
+}
+
+func (fake *FakeMonitor) RetriesCalls(stub func() int64) {
+	fake.retriesMutex.Lock()
+	defer fake.retriesMutex.Unlock()
+	fake.RetriesStub = stub
+}
+
+func (fake *FakeMonitor) RetriesReturns(result1 int64) {
+	fake.retriesMutex.Lock()
+	defer fake.retriesMutex.Unlock()
+	fake.RetriesStub = nil
+	fake.retriesReturns = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *FakeMonitor) RetriesReturnsOnCall(i int, result1 int64) {
+	fake.retriesMutex.Lock()
+	defer fake.retriesMutex.Unlock()
+	fake.RetriesStub = nil
+	if fake.retriesReturnsOnCall == nil {
+		fake.retriesReturnsOnCall = make(map[int]struct {
+			result1 int64
+		})
+	}
+	fake.retriesReturnsOnCall[i] = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *FakeMonitor) SlowQueries() int64 {
+	fake.slowQueriesMutex.Lock()
+	ret, specificReturn := fake.slowQueriesReturnsOnCall[len(fake.slowQueriesArgsForCall)]
+	fake.slowQueriesArgsForCall = append(fake.slowQueriesArgsForCall, struct {
+	}{})
+	stub := fake.SlowQueriesStub
+	fakeReturns := fake.slowQueriesReturns
+	fake.recordInvocation("SlowQueries", []interface{}{})
+	fake.slowQueriesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) SlowQueriesCallCount() int {
+	fake.slowQueriesMutex.RLock()
+	defer fake.slowQueriesMutex.RUnlock()
+	return len(fake.slowQueriesArgsForCall)
+}
+
+func (fake *FakeMonitor) SlowQueriesCalls(stub func() int64) {
+	fake.slowQueriesMutex.Lock()
+	defer fake.slowQueriesMutex.Unlock()
+	fake.SlowQueriesStub = stub
+}
+
+func (fake *FakeMonitor) SlowQueriesReturns(result1 int64) {
+	fake.slowQueriesMutex.Lock()
+	defer fake.slowQueriesMutex.Unlock()
+	fake.SlowQueriesStub = nil
+	fake.slowQueriesReturns = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *FakeMonitor) SlowQueriesReturnsOnCall(i int, result1 int64) {
+	fake.slowQueriesMutex.Lock()
+	defer fake.slowQueriesMutex.Unlock()
+	fake.SlowQueriesStub = nil
+	if fake.slowQueriesReturnsOnCall == nil {
+		fake.slowQueriesReturnsOnCall = make(map[int]struct {
+			result1 int64
+		})
+	}
+	fake.slowQueriesReturnsOnCall[i] = struct {
+		result1 int64
+	}{result1}
+}
+
 func (fake *FakeMonitor) Succeeded() int64 {
 	fake.succeededMutex.Lock()
 	ret, specificReturn := fake.succeededReturnsOnCall[len(fake.succeededArgsForCall)]
@@ -347,6 +830,59 @@ func (fake *FakeMonitor) SucceededReturnsOnCall(i int, result1 int64) {
 	}{result1}
 }
 
+func (fake *FakeMonitor) TimedOut() int64 {
+	fake.timedOutMutex.Lock()
+	ret, specificReturn := fake.timedOutReturnsOnCall[len(fake.timedOutArgsForCall)]
+	fake.timedOutArgsForCall = append(fake.timedOutArgsForCall, struct {
+	}{})
+	stub := fake.TimedOutStub
+	fakeReturns := fake.timedOutReturns
+	fake.recordInvocation("TimedOut", []interface{}{})
+	fake.timedOutMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeMonitor) TimedOutCallCount() int {
+	fake.timedOutMutex.RLock()
+	defer fake.timedOutMutex.RUnlock()
+	return len(fake.timedOutArgsForCall)
+}
+
+func (fake *FakeMonitor) TimedOutCalls(stub func() int64) {
+	fake.timedOutMutex.Lock()
+	defer fake.timedOutMutex.Unlock()
+	fake.TimedOutStub = stub
+}
+
+func (fake *FakeMonitor) TimedOutReturns(result1 int64) {
+	fake.timedOutMutex.Lock()
+	defer fake.timedOutMutex.Unlock()
+	fake.TimedOutStub = nil
+	fake.timedOutReturns = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *FakeMonitor) TimedOutReturnsOnCall(i int, result1 int64) {
+	fake.timedOutMutex.Lock()
+	defer fake.timedOutMutex.Unlock()
+	fake.TimedOutStub = nil
+	if fake.timedOutReturnsOnCall == nil {
+		fake.timedOutReturnsOnCall = make(map[int]struct {
+			result1 int64
+		})
+	}
+	fake.timedOutReturnsOnCall[i] = struct {
+		result1 int64
+	}{result1}
+}
+
 func (fake *FakeMonitor) Total() int64 {
 	fake.totalMutex.Lock()
 	ret, specificReturn := fake.totalReturnsOnCall[len(fake.totalArgsForCall)]
@@ -403,16 +939,36 @@ func (fake *FakeMonitor) TotalReturnsOnCall(i int, result1 int64) {
 func (fake *FakeMonitor) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.connectRetriesMutex.RLock()
+	defer fake.connectRetriesMutex.RUnlock()
 	fake.failedMutex.RLock()
 	defer fake.failedMutex.RUnlock()
 	fake.monitorMutex.RLock()
 	defer fake.monitorMutex.RUnlock()
+	fake.monitorNamedMutex.RLock()
+	defer fake.monitorNamedMutex.RUnlock()
+	fake.readAndResetDurationHistogramMutex.RLock()
+	defer fake.readAndResetDurationHistogramMutex.RUnlock()
 	fake.readAndResetDurationMaxMutex.RLock()
 	defer fake.readAndResetDurationMaxMutex.RUnlock()
 	fake.readAndResetInFlightMaxMutex.RLock()
 	defer fake.readAndResetInFlightMaxMutex.RUnlock()
+	fake.readAndResetQueryStatsMutex.RLock()
+	defer fake.readAndResetQueryStatsMutex.RUnlock()
+	fake.recordConnectRetryMutex.RLock()
+	defer fake.recordConnectRetryMutex.RUnlock()
+	fake.recordRetryMutex.RLock()
+	defer fake.recordRetryMutex.RUnlock()
+	fake.recordSlowQueryMutex.RLock()
+	defer fake.recordSlowQueryMutex.RUnlock()
+	fake.retriesMutex.RLock()
+	defer fake.retriesMutex.RUnlock()
+	fake.slowQueriesMutex.RLock()
+	defer fake.slowQueriesMutex.RUnlock()
 	fake.succeededMutex.RLock()
 	defer fake.succeededMutex.RUnlock()
+	fake.timedOutMutex.RLock()
+	defer fake.timedOutMutex.RUnlock()
 	fake.totalMutex.RLock()
 	defer fake.totalMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
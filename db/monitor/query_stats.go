@@ -0,0 +1,101 @@
+package monitor
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxQueryNames = 100
+	OverflowQueryName    = "other"
+)
+
+type queryNameKey struct{}
+
+// WithQueryName returns a context that causes queries run with it to be
+// tracked under the given name.
+func WithQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryName returns the query name set on ctx with WithQueryName, or "".
+func QueryName(ctx context.Context) string {
+	name, _ := ctx.Value(queryNameKey{}).(string)
+	return name
+}
+
+// QueryStats are the counts for a single query name. Counts are cumulative;
+// DurationMax is the longest duration since the stats were last read.
+type QueryStats struct {
+	Total       int64
+	Succeeded   int64
+	Failed      int64
+	TimedOut    int64
+	DurationMax time.Duration
+}
+
+type queryStats struct {
+	lock     sync.Mutex
+	maxNames int
+	stats    map[string]*QueryStats
+}
+
+func newQueryStats(maxNames int) *queryStats {
+	return &queryStats{
+		maxNames: maxNames,
+		stats:    map[string]*QueryStats{},
+	}
+}
+
+// outcome is how a query is counted.
+type outcome int
+
+const (
+	notCounted outcome = iota
+	succeeded
+	failed
+	timedOut
+)
+
+func (q *queryStats) record(name string, result outcome, duration time.Duration) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	stats, ok := q.stats[name]
+	if !ok {
+		if len(q.stats) >= q.maxNames {
+			name = OverflowQueryName
+		}
+		stats, ok = q.stats[name]
+		if !ok {
+			stats = &QueryStats{}
+			q.stats[name] = stats
+		}
+	}
+
+	stats.Total++
+	switch result {
+	case succeeded:
+		stats.Succeeded++
+	case failed:
+		stats.Failed++
+	case timedOut:
+		stats.TimedOut++
+	}
+	if duration > stats.DurationMax {
+		stats.DurationMax = duration
+	}
+}
+
+func (q *queryStats) readAndReset() map[string]QueryStats {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	result := make(map[string]QueryStats, len(q.stats))
+	for name, stats := range q.stats {
+		result[name] = *stats
+		stats.DurationMax = 0
+	}
+	return result
+}
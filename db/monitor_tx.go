@@ -1,19 +1,35 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"regexp"
 
 	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/db/timeouts"
 	"github.com/jmoiron/sqlx"
 )
 
 //go:generate counterfeiter -o fakes/transaction.go --fake-name Transaction . Transaction
 type Transaction interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	QueryRow(query string, args ...interface{}) RowScanner
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner
 	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
+	QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error)
+	Get(dest interface{}, query string, args ...interface{}) error
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	Select(dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error)
 	Commit() error
 	Rollback() error
+	Savepoint(name string) error
+	RollbackTo(name string) error
+	Release(name string) error
 	Rebind(string) string
 	DriverName() string
 }
@@ -22,47 +38,140 @@ type RowScanner interface {
 	Scan(dest ...interface{}) error
 }
 
+var savepointName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
 type monitoredTx struct {
 	tx      *sqlx.Tx
-	monitor monitor.Monitor
+	monitor queryMonitor
+}
+
+// queryMonitor returns the monitor for a statement run with ctx, which is
+// tracked under the name set on ctx, if any, rather than the name the
+// transaction was begun with.
+func (tx *monitoredTx) queryMonitor(ctx context.Context) queryMonitor {
+	m := tx.monitor
+	if name := monitor.QueryName(ctx); name != "" {
+		m.name = name
+	}
+	return m
 }
 
 func (tx *monitoredTx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.ExecContext(context.Background(), query, args...)
+}
+
+func (tx *monitoredTx) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	var result sql.Result
-	err := tx.monitor.Monitor(func() error {
+	err := tx.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
 		var err error
-		result, err = tx.tx.Exec(query, args...)
+		result, err = tx.tx.ExecContext(ctx, query, args...)
 		return err
 	})
 	return result, err
 }
 
 func (tx *monitoredTx) QueryRow(query string, args ...interface{}) RowScanner {
-	return NewRowScanner(tx.monitor, tx.tx.QueryRow(query, args...))
+	return tx.QueryRowContext(context.Background(), query, args...)
+}
+
+func (tx *monitoredTx) QueryRowContext(ctx context.Context, query string, args ...interface{}) RowScanner {
+	queryMonitor := tx.queryMonitor(ctx)
+	ctx, cancel := timeouts.WithDefault(ctx, queryMonitor.statementTimeout)
+	return &scannableRow{
+		monitor: queryMonitor,
+		scanner: tx.tx.QueryRowContext(ctx, query, args...),
+		query:   query,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
 }
 
 func (tx *monitoredTx) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return tx.QueryxContext(context.Background(), query, args...)
+}
+
+func (tx *monitoredTx) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
 	var result *sqlx.Rows
-	err := tx.monitor.Monitor(func() error {
+	err := tx.queryMonitor(ctx).runRows(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = tx.tx.QueryxContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (tx *monitoredTx) Get(dest interface{}, query string, args ...interface{}) error {
+	return tx.GetContext(context.Background(), dest, query, args...)
+}
+
+func (tx *monitoredTx) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return tx.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		return tx.tx.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (tx *monitoredTx) Select(dest interface{}, query string, args ...interface{}) error {
+	return tx.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (tx *monitoredTx) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return tx.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		return tx.tx.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+func (tx *monitoredTx) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return tx.NamedExecContext(context.Background(), query, arg)
+}
+
+func (tx *monitoredTx) NamedExecContext(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := tx.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
 		var err error
-		result, err = tx.tx.Queryx(query, args...)
+		result, err = tx.tx.NamedExecContext(ctx, query, arg)
 		return err
 	})
 	return result, err
 }
 
 func (tx *monitoredTx) Commit() error {
-	return tx.monitor.Monitor(tx.tx.Commit)
+	return tx.monitor.run("COMMIT", tx.tx.Commit)
 }
 
 func (tx *monitoredTx) Rollback() error {
-	return tx.monitor.Monitor(tx.tx.Rollback)
+	return tx.monitor.run("ROLLBACK", tx.tx.Rollback)
+}
+
+// Savepoint marks a point in the transaction that RollbackTo can return to,
+// undoing only what was done after it.
+func (tx *monitoredTx) Savepoint(name string) error {
+	return tx.execSavepoint("SAVEPOINT", name)
+}
+
+// RollbackTo undoes everything done in the transaction since the savepoint,
+// which remains and can be rolled back to again. On postgres this also
+// recovers a transaction aborted by an error after the savepoint.
+func (tx *monitoredTx) RollbackTo(name string) error {
+	return tx.execSavepoint("ROLLBACK TO SAVEPOINT", name)
+}
+
+// Release discards the savepoint, keeping what was done since it.
+func (tx *monitoredTx) Release(name string) error {
+	return tx.execSavepoint("RELEASE SAVEPOINT", name)
+}
+
+func (tx *monitoredTx) execSavepoint(statement string, name string) error {
+	if !savepointName.MatchString(name) {
+		return fmt.Errorf("invalid savepoint name: %q", name)
+	}
+	_, err := tx.Exec(statement + " " + name)
+	return err
 }
 
 func (tx *monitoredTx) Rebind(query string) string {
 	var result string
 	// #nosec G104 - the Monitor function  only returns an error if the passed function errors, which this doesn't. we just want to log queries in our counters here
-	tx.monitor.Monitor(func() error {
+	tx.monitor.run("", func() error {
 		result = tx.tx.Rebind(query)
 		return nil
 	})
@@ -72,7 +181,7 @@ func (tx *monitoredTx) Rebind(query string) string {
 func (tx *monitoredTx) DriverName() string {
 	var result string
 	// #nosec G104 - the Monitor function  only returns an error if the passed function errors, which this doesn't. we just want to log queries in our counters here
-	tx.monitor.Monitor(func() error {
+	tx.monitor.run("", func() error {
 		result = tx.tx.DriverName()
 		return nil
 	})
@@ -80,16 +189,29 @@ func (tx *monitoredTx) DriverName() string {
 }
 
 type scannableRow struct {
-	monitor monitor.Monitor
+	monitor queryMonitor
 	scanner RowScanner
+	query   string
+
+	// the context the row was queried with, if it has a statement timeout,
+	// which is released once the row is scanned
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
 func NewRowScanner(monitor monitor.Monitor, scanner RowScanner) RowScanner {
-	return &scannableRow{monitor: monitor, scanner: scanner}
+	return &scannableRow{monitor: queryMonitor{monitor: monitor}, scanner: scanner}
 }
 
 func (r *scannableRow) Scan(dest ...interface{}) error {
-	return r.monitor.Monitor(func() error {
-		return r.scanner.Scan(dest...)
+	if r.cancel != nil {
+		defer r.cancel()
+	}
+	return r.monitor.run(r.query, func() error {
+		err := r.scanner.Scan(dest...)
+		if r.ctx != nil {
+			err = timeouts.Wrap(r.ctx, err)
+		}
+		return err
 	})
 }
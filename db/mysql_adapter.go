@@ -15,3 +15,7 @@ func (m MySQLAdapter) ParseDSN(dsn string) (cfg *mysql.Config, err error) {
 func (m MySQLAdapter) RegisterTLSConfig(key string, config *tls.Config) error {
 	return mysql.RegisterTLSConfig(key, config)
 }
+
+func (m MySQLAdapter) DeregisterTLSConfig(key string) {
+	mysql.DeregisterTLSConfig(key)
+}
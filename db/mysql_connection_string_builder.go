@@ -1,11 +1,13 @@
 package db
 
 import (
+	"crypto/sha256"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
 	"net/url"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
@@ -15,19 +17,77 @@ import (
 type mySQLAdapter interface {
 	ParseDSN(dsn string) (cfg *mysql.Config, err error)
 	RegisterTLSConfig(key string, config *tls.Config) error
+	DeregisterTLSConfig(key string)
+}
+
+var mysqlTLSConfigs = &tlsConfigRegistry{refs: map[string]int{}}
+
+// tlsConfigRegistry counts the users of each TLS config registered with the
+// MySQL driver, whose registry is global to the process, and deregisters a
+// config once it has no users left.
+type tlsConfigRegistry struct {
+	lock sync.Mutex
+	refs map[string]int
+}
+
+func (r *tlsConfigRegistry) register(adapter mySQLAdapter, key string, config *tls.Config) (func(), error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	// Registering again replaces the driver's config with an equivalent one,
+	// since the key is derived from the TLS material.
+	if err := adapter.RegisterTLSConfig(key, config); err != nil {
+		return nil, err
+	}
+	r.refs[key]++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { r.release(adapter, key) })
+	}, nil
+}
+
+func (r *tlsConfigRegistry) release(adapter mySQLAdapter, key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.refs[key]--
+	if r.refs[key] > 0 {
+		return
+	}
+	delete(r.refs, key)
+	adapter.DeregisterTLSConfig(key)
 }
 
 type MySQLConnectionStringBuilder struct {
 	MySQLAdapter mySQLAdapter
 }
 
+// Build returns the DSN for config. When config.RequireSSL is set it registers
+// the TLS config with the driver and never releases it; use BuildWithRelease
+// for pools that may be closed.
 func (m *MySQLConnectionStringBuilder) Build(config Config) (string, error) {
+	dsn, _, err := m.BuildWithRelease(config)
+	return dsn, err
+}
+
+// BuildWithRelease returns the DSN for config and a function that releases
+// the registered TLS config once the connections using the DSN are closed.
+// TLS configs are registered under a key derived from the database name and
+// a hash of the TLS material, so configs for the same database with
+// different certificates do not replace each other, while identical configs
+// share one registration that is removed when the last of them is released.
+func (m *MySQLConnectionStringBuilder) BuildWithRelease(config Config) (string, func(), error) {
+	if err := config.validateClientCert(); err != nil {
+		return "", nil, err
+	}
+
 	sqlMode := url.QueryEscape("(SELECT CONCAT(@@sql_mode,',ANSI_QUOTES'))")
 	connString := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&sql_mode=%s", config.User, config.Password, config.Host, config.Port, config.DatabaseName, sqlMode)
 
 	dbConfig, err := m.MySQLAdapter.ParseDSN(connString)
 	if err != nil {
-		return "", fmt.Errorf("parsing db connection string: %s", err)
+		return "", nil, fmt.Errorf("parsing db connection string: %s", err)
 	}
 
 	timeoutDuration := time.Duration(config.Timeout) * time.Second
@@ -35,39 +95,64 @@ func (m *MySQLConnectionStringBuilder) Build(config Config) (string, error) {
 	dbConfig.ReadTimeout = timeoutDuration
 	dbConfig.WriteTimeout = timeoutDuration
 
-	if config.RequireSSL {
-		dbConfig.TLSConfig = fmt.Sprintf("%s-tls", config.DatabaseName)
+	if !config.RequireSSL {
+		return dbConfig.FormatDSN(), func() {}, nil
+	}
+
+	certBytes, err := os.ReadFile(config.CACert)
+	if err != nil {
+		return "", nil, fmt.Errorf("reading db ca cert file: %s", err)
+	}
 
-		certBytes, err := os.ReadFile(config.CACert)
+	caCertPool := x509.NewCertPool()
+	if ok := caCertPool.AppendCertsFromPEM(certBytes); !ok {
+		return "", nil, fmt.Errorf("appending cert to pool from pem - invalid cert bytes")
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: false,
+		RootCAs:            caCertPool,
+	}
+
+	material := sha256.New()
+	material.Write(certBytes)
+
+	if config.ClientCert != "" {
+		clientCertBytes, err := os.ReadFile(config.ClientCert)
 		if err != nil {
-			return "", fmt.Errorf("reading db ca cert file: %s", err)
+			return "", nil, fmt.Errorf("loading db client cert: %s", err)
 		}
-
-		caCertPool := x509.NewCertPool()
-		if ok := caCertPool.AppendCertsFromPEM(certBytes); !ok {
-			return "", fmt.Errorf("appending cert to pool from pem - invalid cert bytes")
+		clientKeyBytes, err := os.ReadFile(config.ClientKey)
+		if err != nil {
+			return "", nil, fmt.Errorf("loading db client cert: %s", err)
 		}
-
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: false,
-			RootCAs:            caCertPool,
+		clientCert, err := tls.X509KeyPair(clientCertBytes, clientKeyBytes)
+		if err != nil {
+			return "", nil, fmt.Errorf("loading db client cert: %s", err)
 		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
 
-		if config.SkipHostnameValidation {
-			tlsConfig.InsecureSkipVerify = true
+		material.Write(clientCertBytes)
+		material.Write(clientKeyBytes)
+	}
 
-			tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
-				return VerifyCertificatesIgnoreHostname(rawCerts, caCertPool)
-			}
-		}
+	if config.SkipHostnameValidation {
+		tlsConfig.InsecureSkipVerify = true
 
-		err = m.MySQLAdapter.RegisterTLSConfig(dbConfig.TLSConfig, tlsConfig)
-		if err != nil {
-			return "", fmt.Errorf("registering mysql tls config: %s", err)
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
+			return VerifyCertificatesIgnoreHostname(rawCerts, caCertPool)
 		}
+		material.Write([]byte("skip-hostname-validation"))
+	}
+
+	dbConfig.TLSConfig = fmt.Sprintf("%s-tls-%x", config.DatabaseName, material.Sum(nil)[:8])
+
+	release, err := mysqlTLSConfigs.register(m.MySQLAdapter, dbConfig.TLSConfig, tlsConfig)
+	if err != nil {
+		return "", nil, fmt.Errorf("registering mysql tls config: %s", err)
 	}
 
-	return dbConfig.FormatDSN(), nil
+	return dbConfig.FormatDSN(), release, nil
 }
 
 func VerifyCertificatesIgnoreHostname(rawCerts [][]byte, caCertPool *x509.CertPool) error {
@@ -5,6 +5,7 @@ import (
 	"encoding/pem"
 	"errors"
 	"os"
+	"sync"
 
 	"code.cloudfoundry.org/cf-networking-helpers/db"
 	"code.cloudfoundry.org/cf-networking-helpers/fakes"
@@ -140,6 +141,18 @@ var _ = Describe("MySQLConnectionStringBuilder", func() {
 			})
 		})
 
+		Context("when a client cert and key are provided but ssl is not required", func() {
+			BeforeEach(func() {
+				config.ClientCert = "/tmp/client-cert"
+				config.ClientKey = "/tmp/client-key"
+			})
+
+			It("returns an error", func() {
+				_, err := mysqlConnectionStringBuilder.Build(config)
+				Expect(err).To(MatchError("`ClientCert` is provided but `RequireSSL` is not set"))
+			})
+		})
+
 		Context("when requiring ssl", func() {
 			var (
 				caCertPool *x509.CertPool
@@ -162,11 +175,11 @@ var _ = Describe("MySQLConnectionStringBuilder", func() {
 			It("builds a tls connection string", func() {
 				connectionString, err := mysqlConnectionStringBuilder.Build(config)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(connectionString).To(Equal("some-user:some-password@tcp(some-host:1234)/some-database?parseTime=true&readTimeout=5s&timeout=5s&tls=some-database-tls&writeTimeout=5s&sql_mode=%28SELECT+CONCAT%28%40%40sql_mode%2C%27%2CANSI_QUOTES%27%29%29"))
 
 				Expect(mySQLAdapter.RegisterTLSConfigCallCount()).To(Equal(1))
 				passedTLSConfigName, passedTLSConfig := mySQLAdapter.RegisterTLSConfigArgsForCall(0)
-				Expect(passedTLSConfigName).To(Equal("some-database-tls"))
+				Expect(passedTLSConfigName).To(MatchRegexp(`^some-database-tls-[0-9a-f]{16}$`))
+				Expect(connectionString).To(Equal("some-user:some-password@tcp(some-host:1234)/some-database?parseTime=true&readTimeout=5s&timeout=5s&tls=" + passedTLSConfigName + "&writeTimeout=5s&sql_mode=%28SELECT+CONCAT%28%40%40sql_mode%2C%27%2CANSI_QUOTES%27%29%29"))
 				Expect(passedTLSConfig.InsecureSkipVerify).To(Equal(false))
 				//lint:ignore SA1019 - ignoring tlsCert.RootCAs.Subjects is deprecated ERR because cert does not come from SystemCertPool.
 				Expect(passedTLSConfig.RootCAs.Subjects()).To(Equal(caCertPool.Subjects()))
@@ -180,11 +193,11 @@ var _ = Describe("MySQLConnectionStringBuilder", func() {
 				It("builds tls config skipping hostname", func() {
 					connectionString, err := mysqlConnectionStringBuilder.Build(config)
 					Expect(err).NotTo(HaveOccurred())
-					Expect(connectionString).To(Equal("some-user:some-password@tcp(some-host:1234)/some-database?parseTime=true&readTimeout=5s&timeout=5s&tls=some-database-tls&writeTimeout=5s&sql_mode=%28SELECT+CONCAT%28%40%40sql_mode%2C%27%2CANSI_QUOTES%27%29%29"))
 
 					Expect(mySQLAdapter.RegisterTLSConfigCallCount()).To(Equal(1))
 					passedTLSConfigName, passedTLSConfig := mySQLAdapter.RegisterTLSConfigArgsForCall(0)
-					Expect(passedTLSConfigName).To(Equal("some-database-tls"))
+					Expect(passedTLSConfigName).To(MatchRegexp(`^some-database-tls-[0-9a-f]{16}$`))
+					Expect(connectionString).To(ContainSubstring("&tls=" + passedTLSConfigName + "&"))
 					Expect(passedTLSConfig.InsecureSkipVerify).To(BeTrue())
 					//lint:ignore SA1019 - ignoring tlsCert.RootCAs.Subjects is deprecated ERR because cert does not come from SystemCertPool.
 					Expect(passedTLSConfig.RootCAs.Subjects()).To(Equal(caCertPool.Subjects()))
@@ -194,6 +207,137 @@ var _ = Describe("MySQLConnectionStringBuilder", func() {
 				})
 			})
 
+			Context("when a client cert and key are provided", func() {
+				BeforeEach(func() {
+					clientCertFile, err := os.CreateTemp("", "")
+					Expect(err).ToNot(HaveOccurred())
+					_, err = clientCertFile.Write([]byte(DATABASE_CLIENT_CERT))
+					Expect(err).NotTo(HaveOccurred())
+
+					clientKeyFile, err := os.CreateTemp("", "")
+					Expect(err).ToNot(HaveOccurred())
+					_, err = clientKeyFile.Write([]byte(DATABASE_CLIENT_KEY))
+					Expect(err).NotTo(HaveOccurred())
+
+					config.ClientCert = clientCertFile.Name()
+					config.ClientKey = clientKeyFile.Name()
+				})
+
+				It("adds the client certificate to the tls config", func() {
+					_, err := mysqlConnectionStringBuilder.Build(config)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(mySQLAdapter.RegisterTLSConfigCallCount()).To(Equal(1))
+					_, passedTLSConfig := mySQLAdapter.RegisterTLSConfigArgsForCall(0)
+					Expect(passedTLSConfig.Certificates).To(HaveLen(1))
+
+					block, _ := pem.Decode([]byte(DATABASE_CLIENT_CERT))
+					Expect(passedTLSConfig.Certificates[0].Certificate[0]).To(Equal(block.Bytes))
+				})
+
+				Context("when the client key does not match the cert", func() {
+					BeforeEach(func() {
+						config.ClientKey = config.ClientCert
+					})
+
+					It("returns an error", func() {
+						_, err := mysqlConnectionStringBuilder.Build(config)
+						Expect(err).To(MatchError(HavePrefix("loading db client cert: ")))
+					})
+				})
+			})
+
+			Context("when only the client cert is provided", func() {
+				BeforeEach(func() {
+					config.ClientCert = "/tmp/client-cert"
+				})
+
+				It("returns an error", func() {
+					_, err := mysqlConnectionStringBuilder.Build(config)
+					Expect(err).To(MatchError("`ClientCert` is provided but `ClientKey` is not"))
+					Expect(mySQLAdapter.RegisterTLSConfigCallCount()).To(Equal(0))
+				})
+			})
+
+			Describe("TLS config registration", func() {
+				registeredName := func(i int) string {
+					name, _ := mySQLAdapter.RegisterTLSConfigArgsForCall(i)
+					return name
+				}
+
+				It("registers configs with different TLS material under different keys", func() {
+					_, err := mysqlConnectionStringBuilder.Build(config)
+					Expect(err).NotTo(HaveOccurred())
+
+					config.SkipHostnameValidation = true
+					_, err = mysqlConnectionStringBuilder.Build(config)
+					Expect(err).NotTo(HaveOccurred())
+
+					otherCAFile, err := os.CreateTemp("", "")
+					Expect(err).ToNot(HaveOccurred())
+					_, err = otherCAFile.Write([]byte(DATABASE_CA_CERT + "\n" + CERTIFICATE_FROM_ANOTHER_CA))
+					Expect(err).NotTo(HaveOccurred())
+					config.CACert = otherCAFile.Name()
+					_, err = mysqlConnectionStringBuilder.Build(config)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(mySQLAdapter.RegisterTLSConfigCallCount()).To(Equal(3))
+					Expect(registeredName(0)).NotTo(Equal(registeredName(1)))
+					Expect(registeredName(1)).NotTo(Equal(registeredName(2)))
+				})
+
+				It("registers identical configs under the same key", func() {
+					_, err := mysqlConnectionStringBuilder.Build(config)
+					Expect(err).NotTo(HaveOccurred())
+					_, err = mysqlConnectionStringBuilder.Build(config)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(registeredName(0)).To(Equal(registeredName(1)))
+				})
+
+				It("deregisters the config when the last user releases it", func() {
+					config.DatabaseName = "released-database"
+
+					_, release1, err := mysqlConnectionStringBuilder.BuildWithRelease(config)
+					Expect(err).NotTo(HaveOccurred())
+					_, release2, err := mysqlConnectionStringBuilder.BuildWithRelease(config)
+					Expect(err).NotTo(HaveOccurred())
+
+					release1()
+					release1()
+					Expect(mySQLAdapter.DeregisterTLSConfigCallCount()).To(Equal(0))
+
+					release2()
+					Expect(mySQLAdapter.DeregisterTLSConfigCallCount()).To(Equal(1))
+					Expect(mySQLAdapter.DeregisterTLSConfigArgsForCall(0)).To(Equal(registeredName(0)))
+				})
+
+				It("can build concurrently", func() {
+					config.DatabaseName = "concurrent-database"
+
+					var wg sync.WaitGroup
+					releases := make(chan func(), 10)
+					for i := 0; i < 10; i++ {
+						wg.Add(1)
+						go func() {
+							defer GinkgoRecover()
+							defer wg.Done()
+							_, release, err := mysqlConnectionStringBuilder.BuildWithRelease(config)
+							Expect(err).NotTo(HaveOccurred())
+							releases <- release
+						}()
+					}
+					wg.Wait()
+					close(releases)
+
+					for release := range releases {
+						release()
+					}
+					Expect(mySQLAdapter.RegisterTLSConfigCallCount()).To(Equal(10))
+					Expect(mySQLAdapter.DeregisterTLSConfigCallCount()).To(Equal(1))
+				})
+			})
+
 			Context("when it can't read the ca cert file", func() {
 				BeforeEach(func() {
 					config.CACert = "/foo/bar"
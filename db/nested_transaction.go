@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+type transactionKey struct{}
+
+// nestedTransaction is the transaction carried by the context passed to
+// WithNestedTransaction functions, and how many savepoints deep it is.
+type nestedTransaction struct {
+	tx    Transaction
+	depth int
+}
+
+// TransactionFromContext returns the transaction a WithNestedTransaction
+// function is running in, if any.
+func TransactionFromContext(ctx context.Context) (Transaction, bool) {
+	nested, ok := ctx.Value(transactionKey{}).(nestedTransaction)
+	return nested.tx, ok
+}
+
+// WithNestedTransaction runs f in a transaction, so that functions using it
+// can be called both on their own and from each other. Called with a context
+// from an enclosing WithNestedTransaction, f runs in a savepoint of that
+// transaction, which is released once f returns, after rolling back to it if
+// f returns an error, leaving the enclosing function to decide whether to
+// carry on. Otherwise f runs in a new transaction begun on conn with opts, as
+// with WithTransaction.
+//
+// f must use the context it is passed to call other such functions. Only the
+// outermost transaction is retried, as an error that aborts a transaction
+// cannot be recovered from in a savepoint.
+func WithNestedTransaction(ctx context.Context, conn *ConnWrapper, opts *sql.TxOptions, f func(context.Context, Transaction) error) error {
+	return newRetriableTransactor(conn).WithNestedTransaction(ctx, opts, f)
+}
+
+// WithNestedTransaction is WithNestedTransaction for transactions begun by r.
+func (r *RetriableTransactor) WithNestedTransaction(ctx context.Context, opts *sql.TxOptions, f func(context.Context, Transaction) error) error {
+	if nested, ok := ctx.Value(transactionKey{}).(nestedTransaction); ok {
+		return withSavepoint(ctx, nested, f)
+	}
+	return r.WithTransaction(ctx, opts, func(tx Transaction) error {
+		return f(context.WithValue(ctx, transactionKey{}, nestedTransaction{tx: tx}), tx)
+	})
+}
+
+func withSavepoint(ctx context.Context, outer nestedTransaction, f func(context.Context, Transaction) error) error {
+	inner := nestedTransaction{tx: outer.tx, depth: outer.depth + 1}
+	// the savepoint is released however f returns, so sibling calls never
+	// overlap and names only need to differ between levels
+	name := fmt.Sprintf("nested_%d", inner.depth)
+
+	if err := inner.tx.Savepoint(name); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	if err := f(context.WithValue(ctx, transactionKey{}, inner), inner.tx); err != nil {
+		if rollbackErr := inner.tx.RollbackTo(name); rollbackErr != nil {
+			return fmt.Errorf("%w (rolling back to savepoint: %s)", err, rollbackErr)
+		}
+		// ROLLBACK TO SAVEPOINT keeps the savepoint
+		if releaseErr := inner.tx.Release(name); releaseErr != nil {
+			return fmt.Errorf("%w (releasing savepoint: %s)", err, releaseErr)
+		}
+		return err
+	}
+
+	if err := inner.tx.Release(name); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
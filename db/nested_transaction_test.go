@@ -0,0 +1,238 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/fakes"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor/monitorfakes"
+	helpersfakes "code.cloudfoundry.org/cf-networking-helpers/fakes"
+	"github.com/lib/pq"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WithNestedTransaction", func() {
+	var (
+		tx         *fakes.Transaction
+		transactor *db.RetriableTransactor
+		beginCalls int
+		ctx        context.Context
+	)
+
+	BeforeEach(func() {
+		tx = &fakes.Transaction{}
+		beginCalls = 0
+		ctx = context.Background()
+		transactor = &db.RetriableTransactor{
+			Beginner: func(context.Context, *sql.TxOptions) (db.Transaction, error) {
+				beginCalls++
+				return tx, nil
+			},
+			Monitor:    &monitorfakes.FakeMonitor{},
+			Sleeper:    &helpersfakes.Sleeper{},
+			MaxRetries: 3,
+		}
+	})
+
+	It("begins and commits a transaction when called on its own", func() {
+		err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, t db.Transaction) error {
+			Expect(t).To(Equal(tx))
+			inner, ok := db.TransactionFromContext(ctx)
+			Expect(ok).To(BeTrue())
+			Expect(inner).To(Equal(tx))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(beginCalls).To(Equal(1))
+		Expect(tx.CommitCallCount()).To(Equal(1))
+		Expect(tx.SavepointCallCount()).To(Equal(0))
+	})
+
+	It("runs nested calls in savepoints of the enclosing transaction", func() {
+		err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, _ db.Transaction) error {
+			return transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, t db.Transaction) error {
+				Expect(t).To(Equal(tx))
+				return transactor.WithNestedTransaction(ctx, nil, func(context.Context, db.Transaction) error {
+					return nil
+				})
+			})
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(beginCalls).To(Equal(1))
+		Expect(tx.CommitCallCount()).To(Equal(1))
+		Expect(tx.SavepointCallCount()).To(Equal(2))
+		Expect(tx.SavepointArgsForCall(0)).To(Equal("nested_1"))
+		Expect(tx.SavepointArgsForCall(1)).To(Equal("nested_2"))
+		Expect(tx.ReleaseCallCount()).To(Equal(2))
+		Expect(tx.ReleaseArgsForCall(0)).To(Equal("nested_2"))
+		Expect(tx.ReleaseArgsForCall(1)).To(Equal("nested_1"))
+		Expect(tx.RollbackToCallCount()).To(Equal(0))
+	})
+
+	Context("when a nested call fails", func() {
+		It("rolls back to and releases its savepoint and lets the enclosing call carry on", func() {
+			err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, _ db.Transaction) error {
+				err := transactor.WithNestedTransaction(ctx, nil, func(context.Context, db.Transaction) error {
+					return errors.New("banana")
+				})
+				Expect(err).To(MatchError("banana"))
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tx.RollbackToCallCount()).To(Equal(1))
+			Expect(tx.RollbackToArgsForCall(0)).To(Equal("nested_1"))
+			Expect(tx.ReleaseCallCount()).To(Equal(1))
+			Expect(tx.ReleaseArgsForCall(0)).To(Equal("nested_1"))
+			Expect(tx.CommitCallCount()).To(Equal(1))
+		})
+
+		It("retries the outermost transaction rather than the nested call", func() {
+			deadlock := &pq.Error{Code: "40P01"}
+
+			err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, _ db.Transaction) error {
+				return transactor.WithNestedTransaction(ctx, nil, func(context.Context, db.Transaction) error {
+					return deadlock
+				})
+			})
+			Expect(err).To(MatchError(deadlock))
+
+			Expect(beginCalls).To(Equal(3))
+			Expect(tx.SavepointCallCount()).To(Equal(3))
+			Expect(tx.RollbackCallCount()).To(Equal(3))
+		})
+
+		It("returns errors from rolling back to the savepoint along with its own", func() {
+			tx.RollbackToReturns(errors.New("connection reset"))
+
+			err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, _ db.Transaction) error {
+				return transactor.WithNestedTransaction(ctx, nil, func(context.Context, db.Transaction) error {
+					return errors.New("banana")
+				})
+			})
+			Expect(err).To(MatchError("banana (rolling back to savepoint: connection reset)"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns errors from releasing the savepoint along with its own", func() {
+			tx.ReleaseReturns(errors.New("connection reset"))
+
+			err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, _ db.Transaction) error {
+				return transactor.WithNestedTransaction(ctx, nil, func(context.Context, db.Transaction) error {
+					return errors.New("banana")
+				})
+			})
+			Expect(err).To(MatchError("banana (releasing savepoint: connection reset)"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the savepoint cannot be created", func() {
+		It("returns an error without running the function", func() {
+			tx.SavepointReturns(errors.New("connection reset"))
+
+			var called bool
+			err := transactor.WithNestedTransaction(ctx, nil, func(ctx context.Context, _ db.Transaction) error {
+				return transactor.WithNestedTransaction(ctx, nil, func(context.Context, db.Transaction) error {
+					called = true
+					return nil
+				})
+			})
+			Expect(err).To(MatchError("create savepoint: connection reset"))
+			Expect(called).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("savepoints on sqlite", func() {
+	var conn *db.ConnWrapper
+
+	BeforeEach(func() {
+		var err error
+		conn, err = db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = conn.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	names := func() []string {
+		var names []string
+		Expect(conn.Select(&names, "SELECT name FROM widgets ORDER BY id")).To(Succeed())
+		return names
+	}
+
+	insert := func(tx db.Transaction, id int, name string) error {
+		_, err := tx.Exec(tx.Rebind("INSERT INTO widgets (id, name) VALUES (?, ?)"), id, name)
+		return err
+	}
+
+	It("rolls back to and releases savepoints", func() {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(insert(tx, 1, "one")).To(Succeed())
+		Expect(tx.Savepoint("before_two")).To(Succeed())
+		Expect(insert(tx, 2, "two")).To(Succeed())
+		Expect(tx.RollbackTo("before_two")).To(Succeed())
+		Expect(insert(tx, 3, "three")).To(Succeed())
+		Expect(tx.Release("before_two")).To(Succeed())
+		Expect(tx.Commit()).To(Succeed())
+
+		Expect(names()).To(Equal([]string{"one", "three"}))
+	})
+
+	It("rejects savepoint names that are not identifiers", func() {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback()
+
+		Expect(tx.Savepoint("a; DROP TABLE widgets")).To(MatchError(`invalid savepoint name: "a; DROP TABLE widgets"`))
+	})
+
+	It("keeps the work of the enclosing transaction when a nested call fails", func() {
+		addWidget := func(ctx context.Context, id int, name string) error {
+			return db.WithNestedTransaction(ctx, conn, nil, func(_ context.Context, tx db.Transaction) error {
+				return insert(tx, id, name)
+			})
+		}
+
+		err := db.WithNestedTransaction(context.Background(), conn, nil, func(ctx context.Context, tx db.Transaction) error {
+			Expect(addWidget(ctx, 1, "one")).To(Succeed())
+			Expect(addWidget(ctx, 1, "duplicate")).NotTo(Succeed())
+			return addWidget(ctx, 2, "two")
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(names()).To(Equal([]string{"one", "two"}))
+
+		Expect(addWidget(context.Background(), 3, "three")).To(Succeed())
+		Expect(names()).To(Equal([]string{"one", "two", "three"}))
+	})
+
+	It("rolls back nested work when the enclosing transaction fails", func() {
+		err := db.WithNestedTransaction(context.Background(), conn, nil, func(ctx context.Context, tx db.Transaction) error {
+			err := db.WithNestedTransaction(ctx, conn, nil, func(_ context.Context, tx db.Transaction) error {
+				return insert(tx, 1, "one")
+			})
+			Expect(err).NotTo(HaveOccurred())
+			return errors.New("banana")
+		})
+		Expect(err).To(MatchError("banana"))
+		Expect(names()).To(BeEmpty())
+	})
+})
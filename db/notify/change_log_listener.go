@@ -0,0 +1,186 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// changeLogListener polls the change log table for databases without
+// notifications. Each poll reads every change made in the last Lookback and
+// skips those already seen, rather than reading from the last id seen, as
+// ids are assigned when a change is recorded and not in the order
+// transactions commit. A transaction committing more than Lookback after it
+// recorded a change may therefore be missed. Changes may also be missed when
+// polls fail for longer than Lookback, so the first poll to succeed after
+// that sends an event for every table.
+type changeLogListener struct {
+	logger     lager.Logger
+	config     db.Config
+	settings   Settings
+	tables     []string
+	subscribed map[string]bool
+	events     chan Event
+
+	conn       *db.ConnWrapper
+	seen       map[int64]int64
+	lastPolled time.Time
+	lastPruned time.Time
+}
+
+type change struct {
+	ID        int64  `db:"id"`
+	Table     string `db:"table_name"`
+	CreatedAt int64  `db:"created_at"`
+}
+
+func (l *changeLogListener) Events() <-chan Event {
+	return l.events
+}
+
+func (l *changeLogListener) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	defer close(l.events)
+
+	// wait for the database while it is unreachable, as the postgres
+	// listener does, staying responsive to signals between attempts
+	for {
+		var err error
+		l.conn, err = db.GetConnectionPool(l.config, context.Background())
+		if err == nil {
+			break
+		}
+		if _, ok := err.(db.RetriableError); !ok {
+			return err
+		}
+		l.logger.Error("connect-failed", err)
+
+		select {
+		case <-signals:
+			return nil
+		case <-time.After(l.settings.PollInterval):
+		}
+	}
+	// #nosec G104 - nothing to do if closing the pool fails on the way out
+	defer l.conn.Close()
+
+	if err := createChangeLog(l.conn, l.settings.Channel); err != nil {
+		return err
+	}
+
+	// changes made before the listener started are already reflected in
+	// whatever the consumer loads once it is ready
+	l.seen = map[int64]int64{}
+	if _, err := l.poll(); err != nil {
+		return err
+	}
+
+	l.logger.Info("polling", lager.Data{"table": l.settings.Channel, "tables": l.tables})
+	close(ready)
+
+	ticker := time.NewTicker(l.settings.PollInterval)
+	defer ticker.Stop()
+
+	var pending pending
+	for {
+		out, next := pending.next(l.events)
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C:
+			changed, err := l.poll()
+			if err != nil {
+				l.logger.Error("poll-failed", err)
+				continue
+			}
+			pending.add(changed...)
+		case out <- next:
+			pending.sent()
+		}
+	}
+}
+
+// poll returns the subscribed tables with changes not seen before, or every
+// table if the last successful poll was more than Lookback ago, and prunes
+// changes older than Retention.
+func (l *changeLogListener) poll() ([]string, error) {
+	now := time.Now()
+	since := now.Add(-l.settings.Lookback).UnixMilli()
+
+	var changes []change
+	err := l.conn.Select(&changes,
+		l.conn.Rebind(fmt.Sprintf("SELECT id, table_name, created_at FROM %s WHERE created_at > ? ORDER BY id", l.settings.Channel)),
+		since)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %s", l.settings.Channel, err)
+	}
+
+	var changed []string
+	for _, c := range changes {
+		if _, ok := l.seen[c.ID]; ok {
+			continue
+		}
+		l.seen[c.ID] = c.CreatedAt
+		if l.subscribed[c.Table] {
+			changed = append(changed, c.Table)
+		}
+	}
+	for id, createdAt := range l.seen {
+		if createdAt <= since {
+			delete(l.seen, id)
+		}
+	}
+
+	if !l.lastPolled.IsZero() && now.Sub(l.lastPolled) > l.settings.Lookback {
+		changed = l.tables
+	}
+	l.lastPolled = now
+
+	if now.Sub(l.lastPruned) >= l.settings.Lookback {
+		_, err := l.conn.Exec(
+			l.conn.Rebind(fmt.Sprintf("DELETE FROM %s WHERE created_at < ?", l.settings.Channel)),
+			now.Add(-l.settings.Retention).UnixMilli())
+		if err != nil {
+			l.logger.Error("prune-failed", err)
+		} else {
+			l.lastPruned = now
+		}
+	}
+
+	return changed, nil
+}
+
+func createChangeLog(conn *db.ConnWrapper, table string) error {
+	var statements []string
+	switch conn.DriverName() {
+	case "mysql":
+		statements = []string{fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+			table_name VARCHAR(255) NOT NULL,
+			created_at BIGINT NOT NULL,
+			INDEX %s_created_at (created_at)
+		)`, table, table)}
+	case "sqlite":
+		// AUTOINCREMENT stops the ids of pruned changes being reused
+		statements = []string{
+			fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				id INTEGER PRIMARY KEY AUTOINCREMENT,
+				table_name VARCHAR(255) NOT NULL,
+				created_at BIGINT NOT NULL
+			)`, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_created_at ON %s (created_at)", table, table),
+		}
+	default:
+		return fmt.Errorf("database type '%s' does not support a change log", conn.DriverName())
+	}
+
+	for _, statement := range statements {
+		if _, err := conn.Exec(statement); err != nil {
+			return fmt.Errorf("creating %s: %s", table, err)
+		}
+	}
+	return nil
+}
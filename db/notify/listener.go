@@ -0,0 +1,99 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit"
+)
+
+// Listener is an ifrit runner that sends an Event on Events for each change
+// to one of its tables recorded by a Notifier. It becomes ready once it is
+// receiving changes, so a consumer that loads its state after that will not
+// miss a change. Events is closed when Run exits.
+//
+// Changes to a table that arrive before the consumer receives the previous
+// event for it are merged into that event. When changes may have been missed,
+// for example while reconnecting to the database, an Event is sent for every
+// table.
+type Listener interface {
+	ifrit.Runner
+	Events() <-chan Event
+}
+
+// NewListener returns a Listener for changes to tables in the database
+// described by config. On postgres it listens for notifications, otherwise it
+// polls the change log table.
+func NewListener(logger lager.Logger, config db.Config, tables []string, opts ...Option) (Listener, error) {
+	settings, err := newSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, errors.New("listener requires at least one table")
+	}
+
+	subscribed := map[string]bool{}
+	for _, table := range tables {
+		subscribed[table] = true
+	}
+
+	switch config.Type {
+	case "postgres":
+		return &postgresListener{
+			logger:     logger.Session("notify-listener"),
+			config:     config,
+			settings:   settings,
+			tables:     tables,
+			subscribed: subscribed,
+			events:     make(chan Event),
+		}, nil
+	case "mysql", "sqlite":
+		return &changeLogListener{
+			logger:     logger.Session("notify-listener"),
+			config:     config,
+			settings:   settings,
+			tables:     tables,
+			subscribed: subscribed,
+			events:     make(chan Event),
+		}, nil
+	default:
+		return nil, fmt.Errorf("database type '%s' is not supported", config.Type)
+	}
+}
+
+// pending holds the tables with changes the consumer has not yet been sent,
+// in the order they changed.
+type pending struct {
+	tables []string
+	queued map[string]bool
+}
+
+func (p *pending) add(tables ...string) {
+	if p.queued == nil {
+		p.queued = map[string]bool{}
+	}
+	for _, table := range tables {
+		if !p.queued[table] {
+			p.queued[table] = true
+			p.tables = append(p.tables, table)
+		}
+	}
+}
+
+// next returns the channel to send the next event on, which is nil and so
+// never ready in a select when nothing is pending.
+func (p *pending) next(events chan Event) (chan<- Event, Event) {
+	if len(p.tables) == 0 {
+		return nil, Event{}
+	}
+	return events, Event{Table: p.tables[0]}
+}
+
+// sent removes the event returned by next.
+func (p *pending) sent() {
+	delete(p.queued, p.tables[0])
+	p.tables = p.tables[1:]
+}
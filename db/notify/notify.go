@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+)
+
+const (
+	DefaultChannel      = "db_changes"
+	DefaultPollInterval = time.Second
+	DefaultLookback     = time.Minute
+	DefaultRetention    = time.Hour
+)
+
+// Event reports that rows in Table have changed. It carries no details of
+// the change, so consumers reread what they need from the table.
+type Event struct {
+	Table string
+}
+
+// Settings holds the settings shared by a Notifier and the Listeners
+// receiving from it, which must agree on the Channel.
+type Settings struct {
+	// Channel is the postgres notification channel, or the name of the
+	// change log table on other databases.
+	Channel string
+
+	// The remaining settings only apply to the change log. It is read every
+	// PollInterval for changes made in the last Lookback, and changes are
+	// deleted after Retention.
+	PollInterval time.Duration
+	Lookback     time.Duration
+	Retention    time.Duration
+}
+
+// Option overrides one of the default Settings.
+type Option func(*Settings)
+
+func WithChannel(name string) Option {
+	return func(s *Settings) { s.Channel = name }
+}
+
+func WithPollInterval(d time.Duration) Option {
+	return func(s *Settings) { s.PollInterval = d }
+}
+
+func WithLookback(d time.Duration) Option {
+	return func(s *Settings) { s.Lookback = d }
+}
+
+func WithRetention(d time.Duration) Option {
+	return func(s *Settings) { s.Retention = d }
+}
+
+var channelPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func newSettings(opts []Option) (Settings, error) {
+	s := Settings{
+		Channel:      DefaultChannel,
+		PollInterval: DefaultPollInterval,
+		Lookback:     DefaultLookback,
+		Retention:    DefaultRetention,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+
+	switch {
+	case !channelPattern.MatchString(s.Channel):
+		return Settings{}, fmt.Errorf("channel must be a valid identifier: %q", s.Channel)
+	case s.PollInterval <= 0:
+		return Settings{}, fmt.Errorf("poll interval must be positive: %s", s.PollInterval)
+	case s.Lookback <= 0:
+		return Settings{}, fmt.Errorf("lookback must be positive: %s", s.Lookback)
+	case s.Retention <= s.Lookback:
+		return Settings{}, fmt.Errorf("retention (%s) must be longer than lookback (%s)", s.Retention, s.Lookback)
+	}
+	return s, nil
+}
+
+// Notifier records changes to tables as part of the transaction that makes
+// them, so that Listeners are told about a change only once it is committed
+// and never about one that is rolled back.
+type Notifier struct {
+	settings Settings
+}
+
+// NewNotifier returns a Notifier for the database conn connects to, creating
+// the change log table if the database needs one.
+func NewNotifier(conn *db.ConnWrapper, opts ...Option) (*Notifier, error) {
+	settings, err := newSettings(opts)
+	if err != nil {
+		return nil, err
+	}
+	if conn.DriverName() != "postgres" {
+		if err := createChangeLog(conn, settings.Channel); err != nil {
+			return nil, err
+		}
+	}
+	return &Notifier{settings: settings}, nil
+}
+
+// Notify records that rows in tables were changed by tx.
+func (n *Notifier) Notify(tx db.Transaction, tables ...string) error {
+	for _, table := range tables {
+		var err error
+		if tx.DriverName() == "postgres" {
+			_, err = tx.Exec("SELECT pg_notify($1, $2)", n.settings.Channel, table)
+		} else {
+			_, err = tx.Exec(
+				tx.Rebind(fmt.Sprintf("INSERT INTO %s (table_name, created_at) VALUES (?, ?)", n.settings.Channel)),
+				table, time.Now().UnixMilli())
+		}
+		if err != nil {
+			return fmt.Errorf("notifying change to %s: %s", table, err)
+		}
+	}
+	return nil
+}
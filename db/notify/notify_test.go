@@ -0,0 +1,307 @@
+package notify_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/notify"
+	_ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Notifier and Listener", func() {
+	var (
+		config   db.Config
+		conn     *db.ConnWrapper
+		logger   *lagertest.TestLogger
+		opts     []notify.Option
+		notifier *notify.Notifier
+		listener notify.Listener
+		process  ifrit.Process
+	)
+
+	notifyChanges := func(commit bool, tables ...string) {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notifier.Notify(tx, tables...)).To(Succeed())
+		if commit {
+			Expect(tx.Commit()).To(Succeed())
+		} else {
+			Expect(tx.Rollback()).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		config = db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}
+		var err error
+		conn, err = db.GetConnectionPool(config, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		logger = lagertest.NewTestLogger("test")
+		opts = []notify.Option{notify.WithPollInterval(10 * time.Millisecond)}
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		notifier, err = notify.NewNotifier(conn, opts...)
+		Expect(err).NotTo(HaveOccurred())
+		listener, err = notify.NewListener(logger, config, []string{"policies", "groups"}, opts...)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if process != nil {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			process = nil
+		}
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("sends an event for committed changes to subscribed tables", func() {
+		process = ifrit.Invoke(listener)
+
+		notifyChanges(true, "policies")
+		Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "policies"})))
+
+		notifyChanges(false, "groups")
+		notifyChanges(true, "other")
+		Consistently(listener.Events(), 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("merges changes the consumer has not received yet", func() {
+		process = ifrit.Invoke(listener)
+
+		notifyChanges(true, "groups", "policies", "groups")
+		Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "groups"})))
+		Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "policies"})))
+		Consistently(listener.Events(), 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("does not send events for changes made before it was ready", func() {
+		notifyChanges(true, "policies")
+		process = ifrit.Invoke(listener)
+
+		Consistently(listener.Events(), 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("closes the events channel when it exits", func() {
+		process = ifrit.Invoke(listener)
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		process = nil
+
+		Eventually(listener.Events()).Should(BeClosed())
+	})
+
+	Context("when changes are older than the retention", func() {
+		BeforeEach(func() {
+			opts = append(opts, notify.WithLookback(20*time.Millisecond), notify.WithRetention(50*time.Millisecond))
+		})
+
+		It("deletes them", func() {
+			process = ifrit.Invoke(listener)
+
+			notifyChanges(true, "policies")
+			Eventually(listener.Events()).Should(Receive())
+
+			Eventually(func() (int, error) {
+				var count int
+				err := conn.Get(&count, "SELECT COUNT(*) FROM db_changes")
+				return count, err
+			}).Should(BeZero())
+		})
+	})
+
+	Context("when polls fail for longer than the lookback", func() {
+		BeforeEach(func() {
+			opts = append(opts, notify.WithLookback(100*time.Millisecond), notify.WithRetention(time.Hour))
+		})
+
+		It("sends an event for every table once polling succeeds again", func() {
+			process = ifrit.Invoke(listener)
+
+			_, err := conn.Exec("ALTER TABLE db_changes RENAME TO db_changes_away")
+			Expect(err).NotTo(HaveOccurred())
+			Eventually(logger).Should(gbytes.Say("poll-failed"))
+			time.Sleep(150 * time.Millisecond)
+			_, err = conn.Exec("ALTER TABLE db_changes_away RENAME TO db_changes")
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "policies"})))
+			Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "groups"})))
+		})
+	})
+
+	Context("when a different channel is used", func() {
+		BeforeEach(func() {
+			opts = append(opts, notify.WithChannel("policy_changes"))
+		})
+
+		It("records changes in a change log table of that name", func() {
+			process = ifrit.Invoke(listener)
+
+			notifyChanges(true, "policies")
+			Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "policies"})))
+
+			var count int
+			Expect(conn.Get(&count, "SELECT COUNT(*) FROM policy_changes")).To(Succeed())
+			Expect(count).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("Notifier and Listener on a database server", func() {
+	var (
+		dbConf   db.Config
+		conn     *db.ConnWrapper
+		notifier *notify.Notifier
+		listener notify.Listener
+		process  ifrit.Process
+	)
+
+	notifyChanges := func(commit bool, tables ...string) {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(notifier.Notify(tx, tables...)).To(Succeed())
+		if commit {
+			Expect(tx.Commit()).To(Succeed())
+		} else {
+			Expect(tx.Rollback()).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		dbConf = testsupport.GetDBConfig()
+		dbConf.DatabaseName = fmt.Sprintf("test_%x", randomGenerator.Int())
+		testsupport.CreateDatabase(dbConf)
+
+		var err error
+		conn, err = db.GetConnectionPool(dbConf, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		opts := []notify.Option{notify.WithPollInterval(10 * time.Millisecond)}
+		notifier, err = notify.NewNotifier(conn, opts...)
+		Expect(err).NotTo(HaveOccurred())
+		listener, err = notify.NewListener(lagertest.NewTestLogger("test"), dbConf, []string{"policies", "groups"}, opts...)
+		Expect(err).NotTo(HaveOccurred())
+
+		process = ifrit.Invoke(listener)
+	})
+
+	AfterEach(func() {
+		if process != nil {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Eventually(listener.Events()).Should(BeClosed())
+			process = nil
+		}
+		Expect(conn.Close()).To(Succeed())
+		testsupport.RemoveDatabase(dbConf)
+	})
+
+	It("sends an event for committed changes to subscribed tables", func() {
+		notifyChanges(true, "groups", "policies", "groups")
+		Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "groups"})))
+		Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "policies"})))
+
+		notifyChanges(false, "groups")
+		notifyChanges(true, "other")
+		Consistently(listener.Events(), 100*time.Millisecond).ShouldNot(Receive())
+	})
+
+	It("sends an event for every table when the listening session is replaced", func() {
+		if dbConf.Type != "postgres" {
+			Skip("only postgres listens on a session")
+		}
+
+		var terminated []bool
+		Expect(conn.Select(&terminated, `SELECT pg_terminate_backend(pid) FROM pg_stat_activity
+			WHERE datname = current_database() AND query LIKE 'LISTEN %'`)).To(Succeed())
+		Expect(terminated).To(Equal([]bool{true}))
+
+		Eventually(listener.Events(), 5*time.Second).Should(Receive(Equal(notify.Event{Table: "policies"})))
+		Eventually(listener.Events()).Should(Receive(Equal(notify.Event{Table: "groups"})))
+	})
+
+	It("indexes the change log by creation time", func() {
+		if dbConf.Type != "mysql" {
+			Skip("only mysql keeps a change log")
+		}
+
+		var count int
+		Expect(conn.Get(&count, `SELECT COUNT(*) FROM information_schema.statistics
+			WHERE table_schema = DATABASE() AND table_name = 'db_changes' AND index_name = 'db_changes_created_at'`)).To(Succeed())
+		Expect(count).To(Equal(1))
+	})
+})
+
+var _ = Describe("Listener on an unreachable database", func() {
+	It("waits for the database without becoming ready, and exits when signalled", func() {
+		logger := lagertest.NewTestLogger("test")
+		config := db.Config{
+			Type:         "mysql",
+			User:         "user",
+			Password:     "password",
+			Host:         "127.0.0.1",
+			Port:         1,
+			DatabaseName: "db",
+			Timeout:      1,
+		}
+		listener, err := notify.NewListener(logger, config, []string{"policies"}, notify.WithPollInterval(10*time.Millisecond))
+		Expect(err).NotTo(HaveOccurred())
+
+		process := ifrit.Background(listener)
+		Eventually(logger).Should(gbytes.Say("connect-failed"))
+		Eventually(logger).Should(gbytes.Say("connect-failed"))
+		Expect(process.Ready()).NotTo(BeClosed())
+
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait(), 5*time.Second).Should(Receive(BeNil()))
+		Eventually(listener.Events()).Should(BeClosed())
+	})
+})
+
+var _ = Describe("NewListener", func() {
+	var config db.Config
+
+	BeforeEach(func() {
+		config = db.Config{Type: "sqlite", DatabaseName: "test.db"}
+	})
+
+	It("requires at least one table", func() {
+		_, err := notify.NewListener(lagertest.NewTestLogger("test"), config, nil)
+		Expect(err).To(MatchError("listener requires at least one table"))
+	})
+
+	It("returns an error for unsupported databases", func() {
+		config.Type = "oracle"
+		_, err := notify.NewListener(lagertest.NewTestLogger("test"), config, []string{"policies"})
+		Expect(err).To(MatchError("database type 'oracle' is not supported"))
+	})
+
+	DescribeTable("rejects invalid settings",
+		func(opt notify.Option, expected string) {
+			_, err := notify.NewListener(lagertest.NewTestLogger("test"), config, []string{"policies"}, opt)
+			Expect(err).To(MatchError(expected))
+		},
+		Entry("channel", notify.WithChannel("changes; DROP TABLE policies"), `channel must be a valid identifier: "changes; DROP TABLE policies"`),
+		Entry("poll interval", notify.WithPollInterval(0), "poll interval must be positive: 0s"),
+		Entry("lookback", notify.WithLookback(-time.Second), "lookback must be positive: -1s"),
+		Entry("retention", notify.WithRetention(time.Minute), "retention (1m0s) must be longer than lookback (1m0s)"),
+	)
+})
@@ -0,0 +1 @@
+package notify // import "code.cloudfoundry.org/cf-networking-helpers/db/notify"
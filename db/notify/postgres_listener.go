@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/lib/pq"
+)
+
+const (
+	minReconnectInterval = time.Second
+	maxReconnectInterval = time.Minute
+
+	// the listener pings its connection so that a dead one is noticed and
+	// replaced even when no notifications are sent
+	pingInterval = 90 * time.Second
+)
+
+type postgresListener struct {
+	logger     lager.Logger
+	config     db.Config
+	settings   Settings
+	tables     []string
+	subscribed map[string]bool
+	events     chan Event
+}
+
+func (l *postgresListener) Events() <-chan Event {
+	return l.events
+}
+
+func (l *postgresListener) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	defer close(l.events)
+
+	connectionString, err := l.config.ConnectionString()
+	if err != nil {
+		return fmt.Errorf("failed to create connection string: %s", err)
+	}
+
+	listener := pq.NewListener(connectionString, minReconnectInterval, maxReconnectInterval, l.logEvent)
+	// #nosec G104 - the listener is not reused, and Close only fails if it is already closed
+	defer listener.Close()
+
+	// Listen blocks while the database is unreachable, so wait for it here
+	// to stay responsive to signals
+	listening := make(chan error, 1)
+	go func() {
+		listening <- listener.Listen(l.settings.Channel)
+	}()
+	select {
+	case <-signals:
+		return nil
+	case err := <-listening:
+		if err != nil {
+			return fmt.Errorf("listening on %s: %s", l.settings.Channel, err)
+		}
+	}
+
+	l.logger.Info("listening", lager.Data{"channel": l.settings.Channel, "tables": l.tables})
+	close(ready)
+
+	ping := time.NewTicker(pingInterval)
+	defer ping.Stop()
+
+	// Ping waits for its reply behind the notifications queued on the
+	// connection, so it runs while the loop keeps receiving them, and only
+	// one runs at a time. It ends with the listener, so pinged is buffered
+	// for a ping that finishes after Run returns.
+	pinged := make(chan error, 1)
+	pinging := false
+
+	var pending pending
+	for {
+		out, next := pending.next(l.events)
+		select {
+		case <-signals:
+			return nil
+		case n := <-listener.Notify:
+			if n == nil {
+				// the connection was replaced, and anything sent in between is lost
+				pending.add(l.tables...)
+				continue
+			}
+			if l.subscribed[n.Extra] {
+				pending.add(n.Extra)
+			}
+		case <-ping.C:
+			if pinging {
+				continue
+			}
+			pinging = true
+			go func() {
+				pinged <- listener.Ping()
+			}()
+		case err := <-pinged:
+			pinging = false
+			if err != nil {
+				l.logger.Error("ping-failed", err)
+			}
+		case out <- next:
+			pending.sent()
+		}
+	}
+}
+
+func (l *postgresListener) logEvent(event pq.ListenerEventType, err error) {
+	switch event {
+	case pq.ListenerEventConnectionAttemptFailed:
+		l.logger.Error("connection-attempt-failed", err)
+	case pq.ListenerEventDisconnected:
+		l.logger.Error("disconnected", err)
+	case pq.ListenerEventReconnected:
+		l.logger.Info("reconnected")
+	}
+}
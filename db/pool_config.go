@@ -0,0 +1,112 @@
+package db
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	DefaultMaxOpenConnections = 100
+	DefaultMaxIdleConnections = 10
+	DefaultConnMaxLifetime    = time.Hour
+)
+
+// PoolConfig holds the settings applied to the sql.DB connection pool, and
+// those used to retry connecting it, which are passed to RetriableConnector.
+// A nil ConnectBackoff is replaced with a DecorrelatedJitterBackoff between
+// DefaultConnectBackoffBase and DefaultConnectBackoffMax.
+type PoolConfig struct {
+	MaxOpenConnections int
+	MaxIdleConnections int
+	ConnMaxLifetime    time.Duration
+	ConnMaxIdleTime    time.Duration
+
+	ConnectBackoff        Backoff
+	ConnectMaxElapsedTime time.Duration
+	OnConnectRetry        func(attempt int, delay time.Duration, err error)
+}
+
+// PoolOption overrides a setting from Config when creating a connection pool.
+type PoolOption func(*PoolConfig)
+
+func WithMaxOpenConnections(n int) PoolOption {
+	return func(p *PoolConfig) { p.MaxOpenConnections = n }
+}
+
+func WithMaxIdleConnections(n int) PoolOption {
+	return func(p *PoolConfig) { p.MaxIdleConnections = n }
+}
+
+func WithConnMaxLifetime(d time.Duration) PoolOption {
+	return func(p *PoolConfig) { p.ConnMaxLifetime = d }
+}
+
+func WithConnMaxIdleTime(d time.Duration) PoolOption {
+	return func(p *PoolConfig) { p.ConnMaxIdleTime = d }
+}
+
+func WithConnectBackoff(b Backoff) PoolOption {
+	return func(p *PoolConfig) { p.ConnectBackoff = b }
+}
+
+func WithConnectMaxElapsedTime(d time.Duration) PoolOption {
+	return func(p *PoolConfig) { p.ConnectMaxElapsedTime = d }
+}
+
+// WithConnectOnRetry sets a hook called before each retry made while
+// connecting. Retries are also counted by the pool's Monitor.
+func WithConnectOnRetry(f func(attempt int, delay time.Duration, err error)) PoolOption {
+	return func(p *PoolConfig) { p.OnConnectRetry = f }
+}
+
+// PoolConfig returns the connection pool settings from c with opts applied.
+// Settings left at zero get the defaults: DefaultMaxOpenConnections,
+// DefaultMaxIdleConnections capped at the max open connections, and
+// DefaultConnMaxLifetime. ConnMaxIdleTime has no default, so idle connections
+// are only closed once they reach their max lifetime.
+func (c Config) PoolConfig(opts ...PoolOption) (PoolConfig, error) {
+	pool := PoolConfig{
+		MaxOpenConnections: c.MaxOpenConnections,
+		MaxIdleConnections: c.MaxIdleConnections,
+		ConnMaxLifetime:    time.Duration(c.ConnMaxLifetime) * time.Second,
+		ConnMaxIdleTime:    time.Duration(c.ConnMaxIdleTime) * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&pool)
+	}
+
+	switch {
+	case pool.MaxOpenConnections < 0:
+		return PoolConfig{}, fmt.Errorf("max open connections must not be negative: %d", pool.MaxOpenConnections)
+	case pool.MaxIdleConnections < 0:
+		return PoolConfig{}, fmt.Errorf("max idle connections must not be negative: %d", pool.MaxIdleConnections)
+	case pool.ConnMaxLifetime < 0:
+		return PoolConfig{}, fmt.Errorf("connection max lifetime must not be negative: %s", pool.ConnMaxLifetime)
+	case pool.ConnMaxIdleTime < 0:
+		return PoolConfig{}, fmt.Errorf("connection max idle time must not be negative: %s", pool.ConnMaxIdleTime)
+	case pool.ConnectMaxElapsedTime < 0:
+		return PoolConfig{}, fmt.Errorf("connect max elapsed time must not be negative: %s", pool.ConnectMaxElapsedTime)
+	}
+
+	if pool.MaxOpenConnections == 0 {
+		pool.MaxOpenConnections = DefaultMaxOpenConnections
+	}
+	if pool.MaxIdleConnections == 0 {
+		pool.MaxIdleConnections = min(DefaultMaxIdleConnections, pool.MaxOpenConnections)
+	}
+	if pool.ConnMaxLifetime == 0 {
+		pool.ConnMaxLifetime = DefaultConnMaxLifetime
+	}
+
+	if pool.MaxIdleConnections > pool.MaxOpenConnections {
+		return PoolConfig{}, fmt.Errorf("max idle connections (%d) must not exceed max open connections (%d)", pool.MaxIdleConnections, pool.MaxOpenConnections)
+	}
+	return pool, nil
+}
+
+func (p PoolConfig) apply(conn *ConnWrapper) {
+	conn.SetMaxOpenConns(p.MaxOpenConnections)
+	conn.SetMaxIdleConns(p.MaxIdleConnections)
+	conn.SetConnMaxLifetime(p.ConnMaxLifetime)
+	conn.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+}
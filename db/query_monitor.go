@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/db/timeouts"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const dbPackagePrefix = "code.cloudfoundry.org/cf-networking-helpers/db."
+
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// queryMonitor runs statements through a monitor.Monitor under a query name,
+// and logs and counts any statement that takes longer than
+// slowQueryThreshold. Statements run with a context that has no deadline are
+// given statementTimeout.
+type queryMonitor struct {
+	monitor            monitor.Monitor
+	name               string
+	logger             lager.Logger
+	slowQueryThreshold time.Duration
+	statementTimeout   time.Duration
+}
+
+func (q queryMonitor) run(query string, f func() error) error {
+	start := time.Now()
+	err := q.monitor.MonitorNamed(q.name, f)
+	q.checkSlowQuery(query, time.Since(start))
+	return err
+}
+
+// runContext runs f like run, with ctx bounded by the statement timeout.
+func (q queryMonitor) runContext(ctx context.Context, query string, f func(context.Context) error) error {
+	ctx, cancel := timeouts.WithDefault(ctx, q.statementTimeout)
+	defer cancel()
+	return q.run(query, func() error {
+		return timeouts.Wrap(ctx, f(ctx))
+	})
+}
+
+// runRows is runContext for statements whose rows, or row, are read after f
+// returns.
+// Cancelling the context would close the rows, so unless f fails the
+// statement timeout also bounds reading them, and the context is released
+// when it expires.
+func (q queryMonitor) runRows(ctx context.Context, query string, f func(context.Context) error) error {
+	ctx, cancel := timeouts.WithDefault(ctx, q.statementTimeout)
+	err := q.run(query, func() error {
+		return timeouts.Wrap(ctx, f(ctx))
+	})
+	if err != nil {
+		cancel()
+	}
+	return err
+}
+
+func (q queryMonitor) checkSlowQuery(query string, duration time.Duration) {
+	if q.slowQueryThreshold <= 0 || duration <= q.slowQueryThreshold {
+		return
+	}
+
+	q.monitor.RecordSlowQuery()
+	if q.logger == nil {
+		return
+	}
+
+	data := lager.Data{
+		"query":     redactQuery(query),
+		"duration":  duration.String(),
+		"threshold": q.slowQueryThreshold.String(),
+		"caller":    caller(),
+	}
+	if q.name != "" {
+		data["query_name"] = q.name
+	}
+	q.logger.Info("slow-query", data)
+}
+
+// redactQuery strips string literals from a statement so that only its shape
+// is logged. Arguments are never logged.
+func redactQuery(query string) string {
+	return stringLiteral.ReplaceAllString(strings.Join(strings.Fields(query), " "), "'?'")
+}
+
+// caller returns the file and line of the first stack frame outside this
+// package, i.e. the code that issued the statement.
+func caller() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, dbPackagePrefix) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
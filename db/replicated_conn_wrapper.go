@@ -0,0 +1,253 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/jmoiron/sqlx"
+)
+
+const DefaultReplicaRetryInterval = 30 * time.Second
+
+// ReplicatedConnWrapper sends reads to read replicas and everything else to
+// the primary. Reads go to the healthy replicas in turn; a read that fails
+// with a connection error falls back to the primary, and the replica is not
+// used again until ReplicaRetryInterval has passed. Which errors count as
+// connection errors is decided by ErrorClassifier, or by the default
+// classifier for the driver if it is nil.
+//
+// Only Query, QueryRow, Queryx, Get and Select and their context variants
+// are routed to replicas. Transactions, Exec and named statements always go
+// to the embedded primary. Reads on a replica are tracked under the query
+// name, and bounded by the SlowQueryThreshold and StatementTimeout, of the
+// primary.
+type ReplicatedConnWrapper struct {
+	*ConnWrapper
+	Replicas             []*ConnWrapper
+	ReplicaRetryInterval time.Duration
+	ErrorClassifier      ErrorClassifier
+
+	healthOnce sync.Once
+	health     *replicaHealth
+}
+
+// replicaHealth is shared by a ReplicatedConnWrapper and the wrappers
+// returned by its Named.
+type replicaHealth struct {
+	lock           sync.Mutex
+	next           int
+	unhealthyUntil map[*ConnWrapper]time.Time
+}
+
+func NewReplicatedConnectionPool(conf Config, logPrefix string, jobPrefix string, logger lager.Logger, opts ...PoolOption) (*ReplicatedConnWrapper, error) {
+	primary, err := NewConnectionPool(conf, logPrefix, jobPrefix, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+	// NewConnectionPool has already validated the pool config.
+	poolConfig, _ := conf.PoolConfig(opts...)
+
+	wrapper := &ReplicatedConnWrapper{
+		ConnWrapper:          primary,
+		ReplicaRetryInterval: DefaultReplicaRetryInterval,
+	}
+
+	// A replica that cannot be reached is kept, marked unhealthy, so that it
+	// is tried again once ReplicaRetryInterval has passed.
+	connector := &Connector{}
+	for _, replicaConf := range conf.ReplicaConfigs() {
+		replica, err := connector.open(replicaConf)
+		if err != nil {
+			logger.Error("replica-invalid", err, lager.Data{"host": replicaConf.Host})
+			continue
+		}
+		poolConfig.apply(replica)
+		replica.Logger = logger
+		wrapper.Replicas = append(wrapper.Replicas, replica)
+
+		timeoutCtx, timeoutCancelFunc := context.WithTimeout(context.Background(), time.Duration(replicaConf.Timeout)*time.Second)
+		err = replica.PingContext(timeoutCtx)
+		timeoutCancelFunc()
+		if err != nil {
+			logger.Error("replica-unavailable", err, lager.Data{"host": replicaConf.Host})
+			wrapper.markUnhealthy(replica, err)
+		}
+	}
+	logger.Info("db replicas connected", lager.Data{"replicas": len(wrapper.Replicas)})
+
+	return wrapper, nil
+}
+
+func (r *ReplicatedConnWrapper) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return r.QueryContext(context.Background(), query, args...)
+}
+
+func (r *ReplicatedConnWrapper) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	var result *sql.Rows
+	err := r.read(func(conn *ConnWrapper) error {
+		var err error
+		result, err = conn.QueryContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (r *ReplicatedConnWrapper) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return r.QueryxContext(context.Background(), query, args...)
+}
+
+func (r *ReplicatedConnWrapper) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	var result *sqlx.Rows
+	err := r.read(func(conn *ConnWrapper) error {
+		var err error
+		result, err = conn.QueryxContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (r *ReplicatedConnWrapper) QueryRow(query string, args ...interface{}) *sql.Row {
+	return r.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext runs query like ConnWrapper.QueryRowContext. A row that
+// fails on a replica has already released its statement timeout, so it is
+// dropped when the read falls back to the primary.
+func (r *ReplicatedConnWrapper) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	var result *sql.Row
+	// #nosec G104 - the error is carried by the returned row, read only decides whether to fall back to the primary
+	r.read(func(conn *ConnWrapper) error {
+		result = conn.QueryRowContext(ctx, query, args...)
+		return result.Err()
+	})
+	return result
+}
+
+func (r *ReplicatedConnWrapper) Get(dest interface{}, query string, args ...interface{}) error {
+	return r.GetContext(context.Background(), dest, query, args...)
+}
+
+func (r *ReplicatedConnWrapper) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(func(conn *ConnWrapper) error {
+		return conn.GetContext(ctx, dest, query, args...)
+	})
+}
+
+func (r *ReplicatedConnWrapper) Select(dest interface{}, query string, args ...interface{}) error {
+	return r.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (r *ReplicatedConnWrapper) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return r.read(func(conn *ConnWrapper) error {
+		return conn.SelectContext(ctx, dest, query, args...)
+	})
+}
+
+// Primary returns the wrapper for the primary database.
+func (r *ReplicatedConnWrapper) Primary() *ConnWrapper {
+	return r.ConnWrapper
+}
+
+// Close closes the primary and all replicas.
+func (r *ReplicatedConnWrapper) Close() error {
+	err := r.ConnWrapper.Close()
+	for _, replica := range r.Replicas {
+		if replicaErr := replica.Close(); err == nil {
+			err = replicaErr
+		}
+	}
+	return err
+}
+
+// Named returns a ReplicatedConnWrapper sharing the same pools, monitors and
+// replica health whose queries, on the primary and the replicas alike, are
+// tracked under name.
+func (r *ReplicatedConnWrapper) Named(name string) *ReplicatedConnWrapper {
+	return &ReplicatedConnWrapper{
+		ConnWrapper:          r.ConnWrapper.Named(name),
+		Replicas:             r.Replicas,
+		ReplicaRetryInterval: r.ReplicaRetryInterval,
+		ErrorClassifier:      r.ErrorClassifier,
+		health:               r.replicaHealth(),
+	}
+}
+
+func (r *ReplicatedConnWrapper) read(f func(*ConnWrapper) error) error {
+	replica := r.nextHealthyReplica()
+	if replica == nil {
+		return f(r.ConnWrapper)
+	}
+
+	err := f(r.onReplica(replica))
+	if err == nil || !r.classifier().IsRetriable(err) {
+		return err
+	}
+
+	r.markUnhealthy(replica, err)
+	return f(r.ConnWrapper)
+}
+
+// onReplica returns replica with the query name and statement settings of
+// the primary.
+func (r *ReplicatedConnWrapper) onReplica(replica *ConnWrapper) *ConnWrapper {
+	conn := *replica
+	conn.queryName = r.ConnWrapper.queryName
+	conn.SlowQueryThreshold = r.ConnWrapper.SlowQueryThreshold
+	conn.StatementTimeout = r.ConnWrapper.StatementTimeout
+	return &conn
+}
+
+func (r *ReplicatedConnWrapper) replicaHealth() *replicaHealth {
+	r.healthOnce.Do(func() {
+		if r.health == nil {
+			r.health = &replicaHealth{unhealthyUntil: map[*ConnWrapper]time.Time{}}
+		}
+	})
+	return r.health
+}
+
+func (r *ReplicatedConnWrapper) nextHealthyReplica() *ConnWrapper {
+	if len(r.Replicas) == 0 {
+		return nil
+	}
+
+	health := r.replicaHealth()
+	health.lock.Lock()
+	defer health.lock.Unlock()
+
+	now := time.Now()
+	for i := 0; i < len(r.Replicas); i++ {
+		replica := r.Replicas[health.next%len(r.Replicas)]
+		health.next++
+		if now.After(health.unhealthyUntil[replica]) {
+			return replica
+		}
+	}
+	return nil
+}
+
+func (r *ReplicatedConnWrapper) markUnhealthy(replica *ConnWrapper, err error) {
+	retryInterval := r.ReplicaRetryInterval
+	if retryInterval == 0 {
+		retryInterval = DefaultReplicaRetryInterval
+	}
+
+	health := r.replicaHealth()
+	health.lock.Lock()
+	health.unhealthyUntil[replica] = time.Now().Add(retryInterval)
+	health.lock.Unlock()
+
+	if r.ConnWrapper.Logger != nil {
+		r.ConnWrapper.Logger.Error("replica-unhealthy", err, lager.Data{"retry_in": retryInterval.String()})
+	}
+}
+
+func (r *ReplicatedConnWrapper) classifier() ErrorClassifier {
+	if r.ErrorClassifier == nil {
+		return ClassifierForDriver(r.DriverName())
+	}
+	return r.ErrorClassifier
+}
@@ -0,0 +1,173 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"github.com/jmoiron/sqlx"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplicatedConnWrapper", func() {
+	var (
+		dbConf     db.Config
+		primary    *db.ConnWrapper
+		replicaA   *db.ConnWrapper
+		replicaB   *db.ConnWrapper
+		replicated *db.ReplicatedConnWrapper
+		logger     *lagertest.TestLogger
+	)
+
+	connect := func() *db.ConnWrapper {
+		conn, err := db.GetConnectionPool(dbConf, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		return conn
+	}
+
+	BeforeEach(func() {
+		dbConf = testsupport.GetDBConfig()
+		dbConf.DatabaseName = fmt.Sprintf("test_%x", randomGenerator.Int())
+		testsupport.CreateDatabase(dbConf)
+
+		logger = lagertest.NewTestLogger("test")
+		primary = connect()
+		primary.Logger = logger
+		replicaA = connect()
+		replicaB = connect()
+
+		_, err := primary.Exec("CREATE TABLE widgets (id INT PRIMARY KEY)")
+		Expect(err).NotTo(HaveOccurred())
+
+		replicated = &db.ReplicatedConnWrapper{
+			ConnWrapper: primary,
+			Replicas:    []*db.ConnWrapper{replicaA, replicaB},
+		}
+	})
+
+	AfterEach(func() {
+		replicated.Close()
+		testsupport.RemoveDatabase(dbConf)
+	})
+
+	It("sends reads to the replicas in turn", func() {
+		for i := 0; i < 4; i++ {
+			var ids []int
+			Expect(replicated.Select(&ids, "SELECT id FROM widgets")).To(Succeed())
+		}
+
+		Expect(replicaA.Monitor.Total()).To(BeEquivalentTo(2))
+		Expect(replicaB.Monitor.Total()).To(BeEquivalentTo(2))
+		Expect(primary.Monitor.Total()).To(BeEquivalentTo(1))
+	})
+
+	It("routes each kind of read to a replica", func() {
+		var count int
+		Expect(replicated.QueryRow("SELECT COUNT(*) FROM widgets").Scan(&count)).To(Succeed())
+		Expect(replicated.Get(&count, "SELECT COUNT(*) FROM widgets")).To(Succeed())
+		rows, err := replicated.Query("SELECT id FROM widgets")
+		Expect(err).NotTo(HaveOccurred())
+		rows.Close()
+		xrows, err := replicated.Queryx("SELECT id FROM widgets")
+		Expect(err).NotTo(HaveOccurred())
+		xrows.Close()
+
+		Expect(replicaA.Monitor.Total() + replicaB.Monitor.Total()).To(BeEquivalentTo(4))
+	})
+
+	It("sends writes and transactions to the primary", func() {
+		_, err := replicated.Exec(replicated.Rebind("INSERT INTO widgets (id) VALUES (?)"), 1)
+		Expect(err).NotTo(HaveOccurred())
+		tx, err := replicated.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.Rollback()).To(Succeed())
+
+		Expect(replicaA.Monitor.Total()).To(BeZero())
+		Expect(replicaB.Monitor.Total()).To(BeZero())
+	})
+
+	It("tracks reads on the replicas under the query name and settings of the primary", func() {
+		replicated.SlowQueryThreshold = time.Nanosecond
+		named := replicated.Named("select-widgets")
+		for i := 0; i < 2; i++ {
+			var ids []int
+			Expect(named.Select(&ids, "SELECT id FROM widgets")).To(Succeed())
+		}
+
+		Expect(replicaA.Monitor.ReadAndResetQueryStats()).To(HaveKeyWithValue("select-widgets", HaveField("Total", BeEquivalentTo(1))))
+		Expect(replicaB.Monitor.ReadAndResetQueryStats()).To(HaveKeyWithValue("select-widgets", HaveField("Total", BeEquivalentTo(1))))
+		Expect(replicaA.Monitor.SlowQueries() + replicaB.Monitor.SlowQueries()).To(BeEquivalentTo(2))
+		Expect(replicaA.SlowQueryThreshold).To(BeZero())
+	})
+
+	It("does not fall back to the primary for query errors", func() {
+		var ids []int
+		err := replicated.Select(&ids, "SELECT nope FROM widgets")
+		Expect(err).To(HaveOccurred())
+
+		Expect(replicaA.Monitor.Failed() + replicaB.Monitor.Failed()).To(BeEquivalentTo(1))
+	})
+
+	Context("when a replica cannot be reached", func() {
+		BeforeEach(func() {
+			unreachable := dbConf
+			unreachable.Port = 1
+			connectionString, err := unreachable.ConnectionString()
+			Expect(err).NotTo(HaveOccurred())
+			raw, err := sqlx.Open(unreachable.Type, connectionString)
+			Expect(err).NotTo(HaveOccurred())
+
+			replicaA = &db.ConnWrapper{DB: raw, Monitor: monitor.New()}
+			replicated.Replicas = []*db.ConnWrapper{replicaA, replicaB}
+			replicated.ReplicaRetryInterval = time.Hour
+		})
+
+		It("falls back to the primary and stops using the replica", func() {
+			for i := 0; i < 4; i++ {
+				var ids []int
+				Expect(replicated.Select(&ids, "SELECT id FROM widgets")).To(Succeed())
+			}
+
+			Expect(replicaA.Monitor.Failed()).To(BeEquivalentTo(1))
+			Expect(primary.Monitor.Succeeded()).To(BeNumerically(">=", 2))
+			Expect(replicaB.Monitor.Succeeded()).To(BeEquivalentTo(3))
+			Expect(logger.LogMessages()).To(ContainElement("test.replica-unhealthy"))
+		})
+
+		It("shares which replicas are unhealthy with named wrappers", func() {
+			var ids []int
+			Expect(replicated.Select(&ids, "SELECT id FROM widgets")).To(Succeed())
+			Expect(replicaA.Monitor.Failed()).To(BeEquivalentTo(1))
+
+			named := replicated.Named("select-widgets")
+			for i := 0; i < 2; i++ {
+				Expect(named.Select(&ids, "SELECT id FROM widgets")).To(Succeed())
+			}
+			Expect(replicaA.Monitor.Failed()).To(BeEquivalentTo(1))
+			Expect(replicaB.Monitor.Succeeded()).To(BeEquivalentTo(2))
+		})
+	})
+
+	Describe("NewReplicatedConnectionPool", func() {
+		It("keeps a replica that cannot be reached, marked unhealthy", func() {
+			conf := dbConf
+			conf.ReplicaHosts = []string{"replica.invalid"}
+			pool, err := db.NewReplicatedConnectionPool(conf, "test", "replicas", logger)
+			Expect(err).NotTo(HaveOccurred())
+			defer pool.Close()
+
+			Expect(pool.Replicas).To(HaveLen(1))
+			Expect(logger.LogMessages()).To(ContainElement("test.replica-unavailable"))
+
+			var ids []int
+			Expect(pool.Select(&ids, "SELECT id FROM widgets")).To(Succeed())
+			Expect(pool.Replicas[0].Monitor.Total()).To(BeZero())
+		})
+	})
+})
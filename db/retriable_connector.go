@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"code.cloudfoundry.org/lager/v3"
@@ -12,22 +13,39 @@ type sleeper interface {
 	Sleep(time.Duration)
 }
 
+type contextSleeper interface {
+	SleepContext(context.Context, time.Duration) error
+}
+
 type SleeperFunc func(time.Duration)
 
 func (sf SleeperFunc) Sleep(duration time.Duration) {
 	sf(duration)
 }
 
+// RetriableConnector retries Connector while it returns a RetriableError, up
+// to MaxRetries attempts. The delay between attempts comes from Backoff, or
+// is a constant RetryInterval if Backoff is nil. If MaxElapsedTime is set, no
+// retry is started that would begin after MaxElapsedTime has passed since the
+// first attempt. OnRetry, if set, is called before each retry.
+//
+// If Sleeper also implements SleepContext(context.Context, time.Duration)
+// error, as TimerSleeper does, retrying stops as soon as ctx is done.
 type RetriableConnector struct {
-	Logger        lager.Logger
-	Connector     func(Config, context.Context) (*ConnWrapper, error)
-	Sleeper       sleeper
-	RetryInterval time.Duration
-	MaxRetries    int
+	Logger         lager.Logger
+	Connector      func(Config, context.Context) (*ConnWrapper, error)
+	Sleeper        sleeper
+	RetryInterval  time.Duration
+	MaxRetries     int
+	Backoff        Backoff
+	MaxElapsedTime time.Duration
+	OnRetry        func(attempt int, delay time.Duration, err error)
 }
 
 func (r *RetriableConnector) GetConnectionPool(dbConfig Config, ctx context.Context) (*ConnWrapper, error) {
 	var attempts int
+	var delay time.Duration
+	start := time.Now()
 	for {
 		attempts++
 
@@ -36,14 +54,44 @@ func (r *RetriableConnector) GetConnectionPool(dbConfig Config, ctx context.Cont
 			return db, nil
 		}
 
-		if _, ok := err.(RetriableError); ok && attempts < r.MaxRetries {
-			r.Logger.Info("retrying due to getting an error", lager.Data{
-				"error": err,
-			})
-			r.Sleeper.Sleep(r.RetryInterval)
-			continue
+		if _, ok := err.(RetriableError); !ok || attempts >= r.MaxRetries {
+			return nil, err
+		}
+
+		delay = r.backoff().NextDelay(attempts, delay)
+		if r.MaxElapsedTime > 0 && time.Since(start)+delay > r.MaxElapsedTime {
+			return nil, err
 		}
 
-		return nil, err
+		r.Logger.Info("retrying due to getting an error", lager.Data{
+			"error":   err,
+			"attempt": attempts,
+			"delay":   delay.String(),
+		})
+		if r.OnRetry != nil {
+			r.OnRetry(attempts, delay, err)
+		}
+
+		dbConfig = dbConfig.RotateHosts()
+		if sleepErr := sleepContext(ctx, r.Sleeper, delay); sleepErr != nil {
+			return nil, fmt.Errorf("stopped retrying after %d attempts: %w: last error: %s", attempts, sleepErr, err)
+		}
+	}
+}
+
+func (r *RetriableConnector) backoff() Backoff {
+	if r.Backoff == nil {
+		return ConstantBackoff{Interval: r.RetryInterval}
+	}
+	return r.Backoff
+}
+
+// sleepContext sleeps for delay, returning early with ctx's error if s
+// implements contextSleeper and ctx is done first.
+func sleepContext(ctx context.Context, s sleeper, delay time.Duration) error {
+	if s, ok := s.(contextSleeper); ok {
+		return s.SleepContext(ctx, delay)
 	}
+	s.Sleep(delay)
+	return nil
 }
@@ -84,6 +84,77 @@ var _ = Describe("RetriableConnector", func() {
 			Expect(logger).To(gbytes.Say("retrying due to getting an error"))
 		})
 
+		It("rotates through the configured hosts between retries", func() {
+			retriableConnector.MaxRetries = 5
+			var hosts []string
+			retriableConnector.Connector = func(config db.Config, context context.Context) (*db.ConnWrapper, error) {
+				hosts = append(hosts, config.Host)
+				if len(hosts) > 3 {
+					return nil, nil
+				}
+				return nil, db.RetriableError{Inner: errors.New("welp")}
+			}
+
+			_, err := retriableConnector.GetConnectionPool(db.Config{Host: "host-1", FailoverHosts: []string{"host-2", "host-3"}}, context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(hosts).To(Equal([]string{"host-1", "host-2", "host-3", "host-1"}))
+		})
+
+		It("waits for the delays given by the backoff", func() {
+			retriableConnector.MaxRetries = 5
+			retriableConnector.Backoff = db.ExponentialBackoff{Initial: time.Second, Max: 3 * time.Second}
+
+			_, err := retriableConnector.GetConnectionPool(db.Config{}, context.Background())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sleeper.SleepCallCount()).To(Equal(3))
+			Expect(sleeper.SleepArgsForCall(0)).To(Equal(time.Second))
+			Expect(sleeper.SleepArgsForCall(1)).To(Equal(2 * time.Second))
+			Expect(sleeper.SleepArgsForCall(2)).To(Equal(3 * time.Second))
+		})
+
+		It("calls the retry hook for each retry", func() {
+			retriableConnector.MaxRetries = 5
+			var attempts []int
+			retriableConnector.OnRetry = func(attempt int, delay time.Duration, err error) {
+				attempts = append(attempts, attempt)
+				Expect(delay).To(Equal(time.Minute))
+				Expect(err).To(MatchError(db.RetriableError{Inner: errors.New("welp")}))
+			}
+
+			_, err := retriableConnector.GetConnectionPool(db.Config{}, context.Background())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts).To(Equal([]int{1, 2, 3}))
+		})
+
+		Context("when the next retry would start after the max elapsed time", func() {
+			It("stops retrying and returns the last error", func() {
+				retriableConnector.MaxRetries = 5
+				retriableConnector.MaxElapsedTime = 30 * time.Second
+
+				_, err := retriableConnector.GetConnectionPool(db.Config{}, context.Background())
+				Expect(err).To(MatchError(db.RetriableError{Inner: errors.New("welp")}))
+				Expect(numTries).To(Equal(1))
+				Expect(sleeper.SleepCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the context is cancelled while sleeping", func() {
+			It("stops retrying", func() {
+				retriableConnector.MaxRetries = 5
+				retriableConnector.Sleeper = db.TimerSleeper{}
+				ctx, cancel := context.WithCancel(context.Background())
+				retriableConnector.OnRetry = func(int, time.Duration, error) {
+					cancel()
+				}
+
+				_, err := retriableConnector.GetConnectionPool(db.Config{}, ctx)
+				Expect(err).To(MatchError(context.Canceled))
+				Expect(err).To(MatchError(ContainSubstring("stopped retrying after 1 attempts")))
+				Expect(numTries).To(Equal(1))
+			})
+		})
+
 		Context("when max retries have occurred", func() {
 			It("stops retrying and returns the last error", func() {
 				retriableConnector.MaxRetries = 10
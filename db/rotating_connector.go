@@ -0,0 +1,201 @@
+package db
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const DefaultCredentialsCheckInterval = 10 * time.Second
+
+// rotatingConnector opens connections with the credentials from a
+// CredentialsProvider. When the credentials change it rebuilds the connector
+// it opens connections with, and connections opened with the old credentials
+// report themselves invalid, so database/sql closes them when they are next
+// taken from or returned to the pool instead of interrupting queries that are
+// using them.
+//
+// The provider is consulted for every new connection, and at most every
+// checkInterval when an idle connection is reused.
+type rotatingConnector struct {
+	config        Config
+	provider      CredentialsProvider
+	logger        lager.Logger
+	checkInterval time.Duration
+
+	lock       sync.Mutex
+	version    string
+	generation int
+	connector  driver.Connector
+	release    func()
+	lastCheck  time.Time
+}
+
+func newRotatingConnector(dbConfig Config, provider CredentialsProvider, checkInterval time.Duration, logger lager.Logger) (*rotatingConnector, error) {
+	r := &rotatingConnector{
+		config:        dbConfig,
+		provider:      provider,
+		logger:        logger,
+		checkInterval: checkInterval,
+	}
+
+	credentials, err := provider.Credentials()
+	if err != nil {
+		return nil, fmt.Errorf("getting db credentials: %s", err)
+	}
+	if err := r.rebuild(credentials); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	connector, generation := r.current(true)
+	conn, err := connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &rotatingConn{Conn: conn, connector: r, generation: generation}, nil
+}
+
+func (r *rotatingConnector) Driver() driver.Driver {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.connector.Driver()
+}
+
+func (r *rotatingConnector) close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.release()
+}
+
+// current returns the connector for the latest credentials and its
+// generation, checking the provider first if force is set or checkInterval
+// has passed since the last check.
+func (r *rotatingConnector) current(force bool) (driver.Connector, int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if force || time.Since(r.lastCheck) >= r.checkInterval {
+		r.lastCheck = time.Now()
+		credentials, err := r.provider.Credentials()
+		if err != nil {
+			r.logger.Error("db-credentials-unavailable", err)
+		} else if credentials.Version != r.version {
+			if err := r.rebuildLocked(credentials); err != nil {
+				r.logger.Error("db-credentials-rotation-failed", err)
+			}
+		}
+	}
+	return r.connector, r.generation
+}
+
+func (r *rotatingConnector) rebuild(credentials Credentials) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.rebuildLocked(credentials)
+}
+
+func (r *rotatingConnector) rebuildLocked(credentials Credentials) error {
+	connector, release, err := newConfigConnector(credentials.apply(r.config))
+	if err != nil {
+		return err
+	}
+
+	if r.connector != nil {
+		r.logger.Info("db-credentials-rotated", lager.Data{"generation": r.generation + 1})
+		// The old connector is only used to open new connections, so
+		// connections it has already opened are unaffected.
+		r.release()
+	}
+	r.connector = connector
+	r.release = release
+	r.version = credentials.Version
+	r.generation++
+	return nil
+}
+
+// rotatingConn is a driver.Conn that is no longer valid once its connector's
+// credentials have changed. It passes the optional driver interfaces through
+// to the wrapped connection.
+type rotatingConn struct {
+	driver.Conn
+	connector  *rotatingConnector
+	generation int
+}
+
+// IsValid is checked by database/sql when a connection is returned to the
+// pool, and ResetSession when an idle connection is taken from it.
+func (c *rotatingConn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok && !validator.IsValid() {
+		return false
+	}
+	return c.current()
+}
+
+func (c *rotatingConn) current() bool {
+	_, generation := c.connector.current(false)
+	return generation == c.generation
+}
+
+func (c *rotatingConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *rotatingConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("driver does not support transaction options")
+	}
+	//lint:ignore SA1019 - Begin is only used for drivers that do not implement BeginTx
+	return c.Conn.Begin()
+}
+
+func (c *rotatingConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rotatingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+	return nil, driver.ErrSkip
+}
+
+func (c *rotatingConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *rotatingConn) ResetSession(ctx context.Context) error {
+	if !c.current() {
+		return driver.ErrBadConn
+	}
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *rotatingConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+	return driver.ErrSkip
+}
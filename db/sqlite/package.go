@@ -0,0 +1 @@
+package sqlite // import "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
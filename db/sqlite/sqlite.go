@@ -0,0 +1,37 @@
+// Package sqlite adds the "sqlite" database type to package db, using a pure
+// Go driver. It is imported for its side effects, so that only binaries that
+// use sqlite carry the driver:
+//
+//	import _ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+package sqlite
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+func init() {
+	// sqlx only knows the bind type of the cgo sqlite3 driver, so without this
+	// Rebind would leave queries for the pure Go sqlite driver unchanged.
+	sqlx.BindDriver("sqlite", sqlx.QUESTION)
+	db.RegisterRetriableTransactionError(isBusy)
+}
+
+// isBusy reports whether err is a busy or locked database, in which case the
+// whole transaction can safely be retried.
+func isBusy(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	// extended result codes keep the primary code in the low byte
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return true
+	}
+	return false
+}
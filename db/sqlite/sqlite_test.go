@@ -0,0 +1,52 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	_ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sqlite", func() {
+	var path string
+
+	BeforeEach(func() {
+		path = filepath.Join(GinkgoT().TempDir(), "test.db")
+	})
+
+	It("registers the driver with question mark placeholders", func() {
+		conn, err := db.GetConnectionPool(db.Config{Type: "sqlite", DatabaseName: path, Timeout: 5}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		defer conn.Close()
+
+		Expect(conn.Rebind("SELECT * FROM widgets WHERE id = ?")).To(Equal("SELECT * FROM widgets WHERE id = ?"))
+		Expect(conn.Ping()).To(Succeed())
+	})
+
+	It("classifies a busy database as a retriable transaction error", func() {
+		raw, err := sql.Open("sqlite", "file:"+path)
+		Expect(err).NotTo(HaveOccurred())
+		defer raw.Close()
+
+		ctx := context.Background()
+		holder, err := raw.Conn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		defer holder.Close()
+		waiter, err := raw.Conn(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		defer waiter.Close()
+
+		_, err = holder.ExecContext(ctx, "BEGIN IMMEDIATE")
+		Expect(err).NotTo(HaveOccurred())
+		defer holder.ExecContext(ctx, "ROLLBACK")
+
+		_, err = waiter.ExecContext(ctx, "BEGIN IMMEDIATE")
+		Expect(err).To(HaveOccurred())
+		Expect(db.IsRetriableTransactionError(err)).To(BeTrue())
+	})
+})
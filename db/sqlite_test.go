@@ -0,0 +1,210 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor/monitorfakes"
+	_ "code.cloudfoundry.org/cf-networking-helpers/db/sqlite"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("sqlite", func() {
+	var conn *db.ConnWrapper
+
+	BeforeEach(func() {
+		var err error
+		conn, err = db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = conn.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("rebinds queries to question mark placeholders", func() {
+		Expect(conn.DriverName()).To(Equal("sqlite"))
+		Expect(conn.Rebind("SELECT * FROM widgets WHERE id = ? AND name = ?")).To(Equal("SELECT * FROM widgets WHERE id = ? AND name = ?"))
+	})
+
+	It("runs monitored queries and transactions", func() {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(tx.DriverName()).To(Equal("sqlite"))
+
+		_, err = tx.Exec(tx.Rebind("INSERT INTO widgets (id, name) VALUES (?, ?)"), 1, "sprocket")
+		Expect(err).NotTo(HaveOccurred())
+
+		var name string
+		Expect(tx.QueryRow(tx.Rebind("SELECT name FROM widgets WHERE id = ?"), 1).Scan(&name)).To(Succeed())
+		Expect(name).To(Equal("sprocket"))
+		Expect(tx.Commit()).To(Succeed())
+
+		var names []string
+		Expect(conn.Select(&names, "SELECT name FROM widgets")).To(Succeed())
+		Expect(names).To(Equal([]string{"sprocket"}))
+
+		Expect(conn.Monitor.Total()).To(BeNumerically(">=", 5))
+		Expect(conn.Monitor.Failed()).To(BeZero())
+	})
+
+	It("runs sqlx queries in transactions", func() {
+		tx, err := conn.Beginx()
+		Expect(err).NotTo(HaveOccurred())
+
+		type widget struct {
+			ID   int    `db:"id"`
+			Name string `db:"name"`
+		}
+		_, err = tx.NamedExec("INSERT INTO widgets (id, name) VALUES (:id, :name)", widget{ID: 1, Name: "sprocket"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tx.NamedExecContext(context.Background(), "INSERT INTO widgets (id, name) VALUES (:id, :name)", widget{ID: 2, Name: "gear"})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = tx.ExecContext(context.Background(), tx.Rebind("UPDATE widgets SET name = ? WHERE id = ?"), "cog", 2)
+		Expect(err).NotTo(HaveOccurred())
+
+		var w widget
+		Expect(tx.Get(&w, tx.Rebind("SELECT id, name FROM widgets WHERE id = ?"), 2)).To(Succeed())
+		Expect(w).To(Equal(widget{ID: 2, Name: "cog"}))
+		Expect(tx.GetContext(context.Background(), &w, tx.Rebind("SELECT id, name FROM widgets WHERE id = ?"), 3)).To(MatchError(sql.ErrNoRows))
+
+		var ws []widget
+		Expect(tx.Select(&ws, "SELECT id, name FROM widgets ORDER BY id")).To(Succeed())
+		Expect(ws).To(Equal([]widget{{ID: 1, Name: "sprocket"}, {ID: 2, Name: "cog"}}))
+
+		var names []string
+		Expect(tx.SelectContext(context.Background(), &names, "SELECT name FROM widgets ORDER BY id")).To(Succeed())
+		Expect(names).To(Equal([]string{"sprocket", "cog"}))
+
+		var name string
+		Expect(tx.QueryRowContext(context.Background(), tx.Rebind("SELECT name FROM widgets WHERE id = ?"), 1).Scan(&name)).To(Succeed())
+		Expect(name).To(Equal("sprocket"))
+
+		rows, err := tx.QueryxContext(context.Background(), "SELECT id FROM widgets")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rows.Close()).To(Succeed())
+
+		Expect(tx.Commit()).To(Succeed())
+		Expect(conn.Monitor.Failed()).To(BeZero())
+	})
+
+	It("tracks statements in a transaction under the name set on their context", func() {
+		fakeMonitor := &monitorfakes.FakeMonitor{}
+		fakeMonitor.MonitorNamedStub = func(_ string, f func() error) error {
+			return f()
+		}
+		conn.Monitor = fakeMonitor
+
+		tx, err := conn.Named("add-widget").Beginx()
+		Expect(err).NotTo(HaveOccurred())
+		defer tx.Rollback()
+
+		_, err = tx.Exec("INSERT INTO widgets (id, name) VALUES (1, 'sprocket')")
+		Expect(err).NotTo(HaveOccurred())
+		var names []string
+		Expect(tx.SelectContext(monitor.WithQueryName(context.Background(), "list-widgets"), &names, "SELECT name FROM widgets")).To(Succeed())
+
+		Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(3))
+		name, _ := fakeMonitor.MonitorNamedArgsForCall(1)
+		Expect(name).To(Equal("add-widget"))
+		name, _ = fakeMonitor.MonitorNamedArgsForCall(2)
+		Expect(name).To(Equal("list-widgets"))
+	})
+
+	It("rolls back transactions", func() {
+		err := db.WithTransaction(context.Background(), conn, nil, func(tx db.Transaction) error {
+			_, err := tx.Exec(tx.Rebind("INSERT INTO widgets (id, name) VALUES (?, ?)"), 1, "sprocket")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = tx.Exec(tx.Rebind("INSERT INTO widgets (id, name) VALUES (?, ?)"), 1, "duplicate")
+			return err
+		})
+		Expect(err).To(HaveOccurred())
+
+		var count int
+		Expect(conn.Get(&count, "SELECT COUNT(*) FROM widgets")).To(Succeed())
+		Expect(count).To(BeZero())
+	})
+
+	Describe("statement timeouts", func() {
+		const endlessQuery = "WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n) SELECT COUNT(*) FROM n"
+
+		BeforeEach(func() {
+			conn.StatementTimeout = 100 * time.Millisecond
+		})
+
+		It("interrupts statements that run too long and counts them as timed out", func() {
+			var count int
+			err := conn.Get(&count, endlessQuery)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+
+			Expect(conn.Monitor.TimedOut()).To(BeEquivalentTo(1))
+			Expect(conn.Monitor.Failed()).To(BeZero())
+		})
+
+		It("interrupts statements in transactions", func() {
+			tx, err := conn.Beginx()
+			Expect(err).NotTo(HaveOccurred())
+			defer tx.Rollback()
+
+			var count int
+			err = tx.QueryRow(endlessQuery).Scan(&count)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+			Expect(conn.Monitor.TimedOut()).To(BeEquivalentTo(1))
+
+			_, err = tx.Exec(tx.Rebind("INSERT INTO widgets (id, name) VALUES (?, ?)"), 1, "sprocket")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("interrupts single row queries and releases their context once scanned", func() {
+			var count int
+			row := conn.QueryRow(endlessQuery)
+			Expect(row.Scan(&count)).To(MatchError(context.DeadlineExceeded))
+			Expect(conn.Monitor.TimedOut()).To(BeEquivalentTo(1))
+
+			Expect(conn.QueryRow("SELECT COUNT(*) FROM widgets").Scan(&count)).To(Succeed())
+			Expect(count).To(BeZero())
+		})
+
+		It("keeps the deadline of a context that has one", func() {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+			defer cancel()
+			ctx, cancelSooner := context.WithTimeout(ctx, 10*time.Millisecond)
+			defer cancelSooner()
+
+			var count int
+			err := conn.GetContext(ctx, &count, endlessQuery)
+			Expect(err).To(MatchError(context.DeadlineExceeded))
+		})
+
+		It("leaves the rows of a query open to be read", func() {
+			_, err := conn.Exec("INSERT INTO widgets (id, name) VALUES (1, 'sprocket'), (2, 'gear')")
+			Expect(err).NotTo(HaveOccurred())
+
+			rows, err := conn.Queryx("SELECT name FROM widgets ORDER BY id")
+			Expect(err).NotTo(HaveOccurred())
+			defer rows.Close()
+
+			var names []string
+			for rows.Next() {
+				var name string
+				Expect(rows.Scan(&name)).To(Succeed())
+				names = append(names, name)
+			}
+			Expect(rows.Err()).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"sprocket", "gear"}))
+		})
+	})
+})
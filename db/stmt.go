@@ -0,0 +1,221 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Stmt is a prepared statement whose executions are monitored like the
+// statements run through the ConnWrapper that prepared it, under its query
+// name unless the context sets one.
+type Stmt struct {
+	stmt  *sqlx.Stmt
+	conn  *ConnWrapper
+	query string
+}
+
+func (c *ConnWrapper) Preparex(query string) (*Stmt, error) {
+	return c.PreparexContext(context.Background(), query)
+}
+
+func (c *ConnWrapper) PreparexContext(ctx context.Context, query string) (*Stmt, error) {
+	var stmt *sqlx.Stmt
+	err := c.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		var err error
+		stmt, err = c.DB.PreparexContext(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Stmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+func (s *Stmt) Exec(args ...interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), args...)
+}
+
+func (s *Stmt) ExecContext(ctx context.Context, args ...interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := s.conn.queryMonitor(ctx).runContext(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		result, err = s.stmt.ExecContext(ctx, args...)
+		return err
+	})
+	return result, err
+}
+
+func (s *Stmt) Query(args ...interface{}) (*sql.Rows, error) {
+	return s.QueryContext(context.Background(), args...)
+}
+
+func (s *Stmt) QueryContext(ctx context.Context, args ...interface{}) (*sql.Rows, error) {
+	var result *sql.Rows
+	err := s.conn.queryMonitor(ctx).runRows(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		result, err = s.stmt.QueryContext(ctx, args...)
+		return err
+	})
+	return result, err
+}
+
+func (s *Stmt) Queryx(args ...interface{}) (*sqlx.Rows, error) {
+	return s.QueryxContext(context.Background(), args...)
+}
+
+func (s *Stmt) QueryxContext(ctx context.Context, args ...interface{}) (*sqlx.Rows, error) {
+	var result *sqlx.Rows
+	err := s.conn.queryMonitor(ctx).runRows(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		result, err = s.stmt.QueryxContext(ctx, args...)
+		return err
+	})
+	return result, err
+}
+
+func (s *Stmt) QueryRow(args ...interface{}) *sql.Row {
+	return s.QueryRowContext(context.Background(), args...)
+}
+
+func (s *Stmt) QueryRowContext(ctx context.Context, args ...interface{}) *sql.Row {
+	var result *sql.Row
+	// #nosec G104 - the error is carried by the returned row
+	s.conn.queryMonitor(ctx).runRows(ctx, s.query, func(ctx context.Context) error {
+		result = s.stmt.QueryRowContext(ctx, args...)
+		return result.Err()
+	})
+	return result
+}
+
+func (s *Stmt) Get(dest interface{}, args ...interface{}) error {
+	return s.GetContext(context.Background(), dest, args...)
+}
+
+func (s *Stmt) GetContext(ctx context.Context, dest interface{}, args ...interface{}) error {
+	return s.conn.queryMonitor(ctx).runContext(ctx, s.query, func(ctx context.Context) error {
+		return s.stmt.GetContext(ctx, dest, args...)
+	})
+}
+
+func (s *Stmt) Select(dest interface{}, args ...interface{}) error {
+	return s.SelectContext(context.Background(), dest, args...)
+}
+
+func (s *Stmt) SelectContext(ctx context.Context, dest interface{}, args ...interface{}) error {
+	return s.conn.queryMonitor(ctx).runContext(ctx, s.query, func(ctx context.Context) error {
+		return s.stmt.SelectContext(ctx, dest, args...)
+	})
+}
+
+// Close closes the statement. Rows already returned by it can still be read.
+func (s *Stmt) Close() error {
+	return s.stmt.Close()
+}
+
+// NamedStmt is a prepared statement with named parameters, monitored like
+// Stmt.
+type NamedStmt struct {
+	stmt  *sqlx.NamedStmt
+	conn  *ConnWrapper
+	query string
+}
+
+func (c *ConnWrapper) PrepareNamed(query string) (*NamedStmt, error) {
+	return c.PrepareNamedContext(context.Background(), query)
+}
+
+func (c *ConnWrapper) PrepareNamedContext(ctx context.Context, query string) (*NamedStmt, error) {
+	var stmt *sqlx.NamedStmt
+	err := c.queryMonitor(ctx).runContext(ctx, query, func(ctx context.Context) error {
+		var err error
+		stmt, err = c.DB.PrepareNamedContext(ctx, query)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &NamedStmt{stmt: stmt, conn: c, query: query}, nil
+}
+
+func (s *NamedStmt) Exec(arg interface{}) (sql.Result, error) {
+	return s.ExecContext(context.Background(), arg)
+}
+
+func (s *NamedStmt) ExecContext(ctx context.Context, arg interface{}) (sql.Result, error) {
+	var result sql.Result
+	err := s.conn.queryMonitor(ctx).runContext(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		result, err = s.stmt.ExecContext(ctx, arg)
+		return err
+	})
+	return result, err
+}
+
+func (s *NamedStmt) Query(arg interface{}) (*sql.Rows, error) {
+	return s.QueryContext(context.Background(), arg)
+}
+
+func (s *NamedStmt) QueryContext(ctx context.Context, arg interface{}) (*sql.Rows, error) {
+	var result *sql.Rows
+	err := s.conn.queryMonitor(ctx).runRows(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		result, err = s.stmt.QueryContext(ctx, arg)
+		return err
+	})
+	return result, err
+}
+
+func (s *NamedStmt) Queryx(arg interface{}) (*sqlx.Rows, error) {
+	return s.QueryxContext(context.Background(), arg)
+}
+
+func (s *NamedStmt) QueryxContext(ctx context.Context, arg interface{}) (*sqlx.Rows, error) {
+	var result *sqlx.Rows
+	err := s.conn.queryMonitor(ctx).runRows(ctx, s.query, func(ctx context.Context) error {
+		var err error
+		result, err = s.stmt.QueryxContext(ctx, arg)
+		return err
+	})
+	return result, err
+}
+
+func (s *NamedStmt) QueryRow(arg interface{}) *sqlx.Row {
+	return s.QueryRowContext(context.Background(), arg)
+}
+
+func (s *NamedStmt) QueryRowContext(ctx context.Context, arg interface{}) *sqlx.Row {
+	var result *sqlx.Row
+	// #nosec G104 - the error is carried by the returned row
+	s.conn.queryMonitor(ctx).runRows(ctx, s.query, func(ctx context.Context) error {
+		result = s.stmt.QueryRowContext(ctx, arg)
+		return result.Err()
+	})
+	return result
+}
+
+func (s *NamedStmt) Get(dest interface{}, arg interface{}) error {
+	return s.GetContext(context.Background(), dest, arg)
+}
+
+func (s *NamedStmt) GetContext(ctx context.Context, dest interface{}, arg interface{}) error {
+	return s.conn.queryMonitor(ctx).runContext(ctx, s.query, func(ctx context.Context) error {
+		return s.stmt.GetContext(ctx, dest, arg)
+	})
+}
+
+func (s *NamedStmt) Select(dest interface{}, arg interface{}) error {
+	return s.SelectContext(context.Background(), dest, arg)
+}
+
+func (s *NamedStmt) SelectContext(ctx context.Context, dest interface{}, arg interface{}) error {
+	return s.conn.queryMonitor(ctx).runContext(ctx, s.query, func(ctx context.Context) error {
+		return s.stmt.SelectContext(ctx, dest, arg)
+	})
+}
+
+// Close closes the statement. Rows already returned by it can still be read.
+func (s *NamedStmt) Close() error {
+	return s.stmt.Close()
+}
@@ -0,0 +1,246 @@
+package db
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const DefaultStmtCacheSize = 100
+
+// StmtCache runs statements as prepared statements, keeping the most
+// recently used size of them prepared, keyed by their SQL text. Each
+// statement is prepared on every connection it runs on, so the database
+// holds up to size statements per connection in the pool.
+//
+// Statements are run through the cache rather than handed out, so that a
+// statement evicted while it is running is only closed once it is done.
+type StmtCache struct {
+	conn *ConnWrapper
+	size int
+
+	lock  sync.Mutex
+	stmts map[string]*list.Element
+	lru   *list.List
+
+	hits      int64
+	misses    int64
+	evictions int64
+}
+
+type cachedStmt struct {
+	*Stmt
+	inUse   int
+	evicted bool
+}
+
+// NewStmtCache returns a StmtCache preparing statements on conn. A size less
+// than 1 is replaced with DefaultStmtCacheSize.
+func NewStmtCache(conn *ConnWrapper, size int) *StmtCache {
+	if size < 1 {
+		size = DefaultStmtCacheSize
+	}
+	return &StmtCache{
+		conn:  conn,
+		size:  size,
+		stmts: map[string]*list.Element{},
+		lru:   list.New(),
+	}
+}
+
+func (c *StmtCache) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return c.ExecContext(context.Background(), query, args...)
+}
+
+func (c *StmtCache) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(stmt)
+	return stmt.ExecContext(ctx, args...)
+}
+
+func (c *StmtCache) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return c.QueryContext(context.Background(), query, args...)
+}
+
+// QueryContext runs query and returns its rows, which stay readable if the
+// statement is evicted before they are closed.
+func (c *StmtCache) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(stmt)
+	return stmt.QueryContext(ctx, args...)
+}
+
+func (c *StmtCache) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return c.QueryxContext(context.Background(), query, args...)
+}
+
+func (c *StmtCache) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	stmt, err := c.acquire(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer c.release(stmt)
+	return stmt.QueryxContext(ctx, args...)
+}
+
+func (c *StmtCache) QueryRow(query string, args ...interface{}) *sql.Row {
+	return c.QueryRowContext(context.Background(), query, args...)
+}
+
+// QueryRowContext runs query and returns its row. If the statement cannot be
+// prepared, it is run unprepared, so that its error is returned by the row's
+// Scan.
+func (c *StmtCache) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	stmt, err := c.acquire(ctx, query)
+	if err != nil {
+		return c.conn.QueryRowContext(ctx, query, args...)
+	}
+	defer c.release(stmt)
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+func (c *StmtCache) Get(dest interface{}, query string, args ...interface{}) error {
+	return c.GetContext(context.Background(), dest, query, args...)
+}
+
+func (c *StmtCache) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, err := c.acquire(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer c.release(stmt)
+	return stmt.GetContext(ctx, dest, args...)
+}
+
+func (c *StmtCache) Select(dest interface{}, query string, args ...interface{}) error {
+	return c.SelectContext(context.Background(), dest, query, args...)
+}
+
+func (c *StmtCache) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, err := c.acquire(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer c.release(stmt)
+	return stmt.SelectContext(ctx, dest, args...)
+}
+
+// Len returns the number of statements in the cache.
+func (c *StmtCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.lru.Len()
+}
+
+// Hits returns the number of statements run that were already prepared.
+func (c *StmtCache) Hits() int64 {
+	return atomic.LoadInt64(&c.hits)
+}
+
+// Misses returns the number of statements run that had to be prepared.
+func (c *StmtCache) Misses() int64 {
+	return atomic.LoadInt64(&c.misses)
+}
+
+// Evictions returns the number of statements closed to make room for others.
+func (c *StmtCache) Evictions() int64 {
+	return atomic.LoadInt64(&c.evictions)
+}
+
+// Close closes every statement in the cache and empties it. Statements that
+// are running are closed once they are done.
+func (c *StmtCache) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var firstErr error
+	for c.lru.Len() > 0 {
+		if err := c.remove(c.lru.Back()); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (c *StmtCache) acquire(ctx context.Context, query string) (*cachedStmt, error) {
+	if stmt := c.lookup(query); stmt != nil {
+		atomic.AddInt64(&c.hits, 1)
+		return stmt, nil
+	}
+	atomic.AddInt64(&c.misses, 1)
+
+	// prepare without holding the lock, as it is a round trip to the database
+	prepared, err := c.conn.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.stmts[query]; ok {
+		// another caller prepared the same statement in the meantime
+		// #nosec G104 - the statement was never used, so closing it cannot fail in a way that matters
+		prepared.Close()
+		stmt := elem.Value.(*cachedStmt)
+		c.lru.MoveToFront(elem)
+		stmt.inUse++
+		return stmt, nil
+	}
+
+	stmt := &cachedStmt{Stmt: prepared, inUse: 1}
+	c.stmts[query] = c.lru.PushFront(stmt)
+	for c.lru.Len() > c.size {
+		// #nosec G104 - the evicted statement is no longer used, so there is nothing to do if closing it fails
+		c.remove(c.lru.Back())
+		atomic.AddInt64(&c.evictions, 1)
+	}
+	return stmt, nil
+}
+
+func (c *StmtCache) lookup(query string) *cachedStmt {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	elem, ok := c.stmts[query]
+	if !ok {
+		return nil
+	}
+	c.lru.MoveToFront(elem)
+	stmt := elem.Value.(*cachedStmt)
+	stmt.inUse++
+	return stmt
+}
+
+func (c *StmtCache) release(stmt *cachedStmt) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	stmt.inUse--
+	if stmt.evicted && stmt.inUse == 0 {
+		// #nosec G104 - the evicted statement is no longer used, so there is nothing to do if closing it fails
+		stmt.Close()
+	}
+}
+
+// remove takes elem out of the cache and closes its statement, or marks it
+// to be closed when released if it is running.
+func (c *StmtCache) remove(elem *list.Element) error {
+	stmt := c.lru.Remove(elem).(*cachedStmt)
+	delete(c.stmts, stmt.query)
+	stmt.evicted = true
+	if stmt.inUse == 0 {
+		return stmt.Close()
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package db_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StmtCache", func() {
+	var (
+		conn  *db.ConnWrapper
+		cache *db.StmtCache
+	)
+
+	BeforeEach(func() {
+		var err error
+		conn, err = db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = conn.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = conn.Exec("INSERT INTO widgets (id, name) VALUES (1, 'one'), (2, 'two')")
+		Expect(err).NotTo(HaveOccurred())
+
+		cache = db.NewStmtCache(conn, 2)
+	})
+
+	AfterEach(func() {
+		Expect(cache.Close()).To(Succeed())
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	It("prepares each statement once", func() {
+		total := conn.Monitor.Total()
+		var name string
+		for i := 0; i < 3; i++ {
+			Expect(cache.Get(&name, "SELECT name FROM widgets WHERE id = ?", 1)).To(Succeed())
+			Expect(name).To(Equal("one"))
+		}
+
+		Expect(cache.Len()).To(Equal(1))
+		Expect(cache.Misses()).To(BeEquivalentTo(1))
+		Expect(cache.Hits()).To(BeEquivalentTo(2))
+		Expect(conn.Monitor.Total() - total).To(BeEquivalentTo(4))
+	})
+
+	It("evicts the least recently used statement when full", func() {
+		var names []string
+		Expect(cache.Select(&names, "SELECT name FROM widgets ORDER BY id")).To(Succeed())
+		_, err := cache.Exec("UPDATE widgets SET name = ? WHERE id = ?", "uno", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.Select(&names, "SELECT name FROM widgets ORDER BY id")).To(Succeed())
+		Expect(names).To(Equal([]string{"uno", "two"}))
+
+		var count int
+		Expect(cache.QueryRow("SELECT COUNT(*) FROM widgets").Scan(&count)).To(Succeed())
+		Expect(count).To(Equal(2))
+
+		Expect(cache.Len()).To(Equal(2))
+		Expect(cache.Evictions()).To(BeEquivalentTo(1))
+
+		_, err = cache.Exec("UPDATE widgets SET name = ? WHERE id = ?", "one", 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.Misses()).To(BeEquivalentTo(4))
+		Expect(cache.Evictions()).To(BeEquivalentTo(2))
+	})
+
+	It("keeps rows readable when their statement is evicted", func() {
+		rows, err := cache.Queryx("SELECT name FROM widgets ORDER BY id")
+		Expect(err).NotTo(HaveOccurred())
+		defer rows.Close()
+
+		Expect(cache.QueryRow("SELECT COUNT(*) FROM widgets").Scan(new(int))).To(Succeed())
+		Expect(cache.QueryRow("SELECT MAX(id) FROM widgets").Scan(new(int))).To(Succeed())
+		Expect(cache.Evictions()).To(BeEquivalentTo(1))
+
+		var names []string
+		for rows.Next() {
+			var name string
+			Expect(rows.Scan(&name)).To(Succeed())
+			names = append(names, name)
+		}
+		Expect(rows.Err()).NotTo(HaveOccurred())
+		Expect(names).To(Equal([]string{"one", "two"}))
+	})
+
+	It("returns errors from preparing the statement", func() {
+		err := cache.Get(new(int), "SELECT id FROM gadgets")
+		Expect(err).To(MatchError(ContainSubstring("no such table")))
+		Expect(cache.QueryRow("SELECT id FROM gadgets").Scan(new(int))).To(MatchError(ContainSubstring("no such table")))
+		Expect(cache.Len()).To(BeZero())
+	})
+
+	It("can be used concurrently", func() {
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				for j := 0; j < 20; j++ {
+					var id int
+					query := fmt.Sprintf("SELECT id FROM widgets WHERE id = ? AND %d = %d", (i+j)%4, (i+j)%4)
+					Expect(cache.Get(&id, query, 2)).To(Succeed())
+					Expect(id).To(Equal(2))
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		Expect(cache.Len()).To(Equal(2))
+		Expect(cache.Hits() + cache.Misses()).To(BeEquivalentTo(160))
+	})
+})
@@ -0,0 +1,146 @@
+package db_test
+
+import (
+	"context"
+	"path/filepath"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor/monitorfakes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("prepared statements", func() {
+	var (
+		conn        *db.ConnWrapper
+		fakeMonitor *monitorfakes.FakeMonitor
+		ctx         context.Context
+	)
+
+	BeforeEach(func() {
+		var err error
+		conn, err = db.GetConnectionPool(db.Config{
+			Type:         "sqlite",
+			DatabaseName: filepath.Join(GinkgoT().TempDir(), "test.db"),
+			Timeout:      5,
+		}, context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = conn.Exec("CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT NOT NULL)")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = conn.Exec("INSERT INTO widgets (id, name) VALUES (1, 'one'), (2, 'two')")
+		Expect(err).NotTo(HaveOccurred())
+
+		fakeMonitor = &monitorfakes.FakeMonitor{}
+		fakeMonitor.MonitorNamedStub = func(_ string, f func() error) error {
+			return f()
+		}
+		conn.Monitor = fakeMonitor
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		Expect(conn.Close()).To(Succeed())
+	})
+
+	type widget struct {
+		ID   int    `db:"id"`
+		Name string `db:"name"`
+	}
+
+	Describe("Stmt", func() {
+		var stmt *db.Stmt
+
+		BeforeEach(func() {
+			var err error
+			stmt, err = conn.Named("select-widget").PreparexContext(ctx, "SELECT id, name FROM widgets WHERE id = ?")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(stmt.Close()).To(Succeed())
+		})
+
+		It("monitors preparing the statement", func() {
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(1))
+		})
+
+		It("monitors GetContext under the name it was prepared with", func() {
+			var w widget
+			Expect(stmt.GetContext(ctx, &w, 2)).To(Succeed())
+			Expect(w).To(Equal(widget{ID: 2, Name: "two"}))
+
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(2))
+			name, _ := fakeMonitor.MonitorNamedArgsForCall(1)
+			Expect(name).To(Equal("select-widget"))
+		})
+
+		It("prefers the name set on the context", func() {
+			var ws []widget
+			Expect(stmt.SelectContext(monitor.WithQueryName(ctx, "list-widgets"), &ws, 1)).To(Succeed())
+			Expect(ws).To(Equal([]widget{{ID: 1, Name: "one"}}))
+
+			name, _ := fakeMonitor.MonitorNamedArgsForCall(1)
+			Expect(name).To(Equal("list-widgets"))
+		})
+
+		It("monitors QueryxContext", func() {
+			rows, err := stmt.QueryxContext(ctx, 1)
+			Expect(err).NotTo(HaveOccurred())
+			defer rows.Close()
+
+			Expect(rows.Next()).To(BeTrue())
+			var w widget
+			Expect(rows.StructScan(&w)).To(Succeed())
+			Expect(w.Name).To(Equal("one"))
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(2))
+		})
+
+		It("monitors QueryRowContext", func() {
+			var name string
+			var id int
+			Expect(stmt.QueryRowContext(ctx, 2).Scan(&id, &name)).To(Succeed())
+			Expect(name).To(Equal("two"))
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(2))
+		})
+
+		It("monitors ExecContext", func() {
+			del, err := conn.Preparex("DELETE FROM widgets WHERE id = ?")
+			Expect(err).NotTo(HaveOccurred())
+			defer del.Close()
+
+			result, err := del.Exec(1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RowsAffected()).To(BeEquivalentTo(1))
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(3))
+		})
+
+		It("returns an error when the statement cannot be prepared", func() {
+			_, err := conn.Preparex("SELECT * FROM gadgets")
+			Expect(err).To(MatchError(ContainSubstring("no such table")))
+		})
+	})
+
+	Describe("NamedStmt", func() {
+		It("runs and monitors statements with named parameters", func() {
+			insert, err := conn.PrepareNamed("INSERT INTO widgets (id, name) VALUES (:id, :name)")
+			Expect(err).NotTo(HaveOccurred())
+			defer insert.Close()
+
+			_, err = insert.ExecContext(ctx, widget{ID: 3, Name: "three"})
+			Expect(err).NotTo(HaveOccurred())
+
+			sel, err := conn.PrepareNamedContext(ctx, "SELECT id, name FROM widgets WHERE name = :name")
+			Expect(err).NotTo(HaveOccurred())
+			defer sel.Close()
+
+			var w widget
+			Expect(sel.Get(&w, map[string]interface{}{"name": "three"})).To(Succeed())
+			Expect(w).To(Equal(widget{ID: 3, Name: "three"}))
+
+			Expect(fakeMonitor.MonitorNamedCallCount()).To(Equal(4))
+		})
+	})
+})
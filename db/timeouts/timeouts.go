@@ -1 +1,48 @@
 package timeouts
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithDefault returns ctx bounded by timeout, unless ctx already has a
+// deadline or timeout is not positive, in which case ctx is returned as is
+// along with a cancel func that does nothing.
+func WithDefault(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	if _, ok := ctx.Deadline(); ok {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// Wrap returns err so that it matches context.DeadlineExceeded when it was
+// returned after the deadline of ctx passed. Drivers often report an
+// interrupted statement with an error of their own, such as a cancelled query
+// or a closed connection, which does not say that it ran out of time.
+func Wrap(ctx context.Context, err error) error {
+	if err == nil || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if ctx.Err() != context.DeadlineExceeded {
+		return err
+	}
+	return &timeoutError{err: err}
+}
+
+// timeoutError reads as the driver's error, so that callers checking for it
+// are unaffected.
+type timeoutError struct {
+	err error
+}
+
+func (e *timeoutError) Error() string {
+	return e.err.Error()
+}
+
+func (e *timeoutError) Unwrap() []error {
+	return []error{e.err, context.DeadlineExceeded}
+}
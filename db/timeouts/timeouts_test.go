@@ -2,6 +2,7 @@ package timeouts_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os/exec"
@@ -10,6 +11,7 @@ import (
 	"time"
 
 	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/timeouts"
 	"code.cloudfoundry.org/cf-networking-helpers/testsupport"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -254,6 +256,68 @@ var _ = Describe("Timeout", func() {
 	})
 })
 
+var _ = Describe("WithDefault", func() {
+	It("bounds a context without a deadline by the timeout", func() {
+		ctx, cancel := timeouts.WithDefault(context.Background(), time.Minute)
+		defer cancel()
+
+		deadline, ok := ctx.Deadline()
+		Expect(ok).To(BeTrue())
+		Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+	})
+
+	It("keeps the deadline of a context that has one", func() {
+		parent, cancelParent := context.WithTimeout(context.Background(), time.Hour)
+		defer cancelParent()
+
+		ctx, cancel := timeouts.WithDefault(parent, time.Minute)
+		defer cancel()
+		Expect(ctx).To(Equal(parent))
+	})
+
+	It("does nothing when the timeout is zero", func() {
+		ctx, cancel := timeouts.WithDefault(context.Background(), 0)
+		defer cancel()
+
+		_, ok := ctx.Deadline()
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("Wrap", func() {
+	var driverErr error
+
+	BeforeEach(func() {
+		driverErr = errors.New("pq: canceling statement due to user request")
+	})
+
+	It("makes errors returned after the deadline match context.DeadlineExceeded", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+		defer cancel()
+		<-ctx.Done()
+
+		err := timeouts.Wrap(ctx, driverErr)
+		Expect(err).To(MatchError(context.DeadlineExceeded))
+		Expect(err).To(MatchError(driverErr))
+		Expect(err.Error()).To(Equal(driverErr.Error()))
+	})
+
+	It("leaves errors returned before the deadline as they are", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		Expect(timeouts.Wrap(ctx, driverErr)).To(BeIdenticalTo(driverErr))
+		Expect(timeouts.Wrap(ctx, nil)).To(BeNil())
+	})
+
+	It("leaves errors from cancelled contexts as they are", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		Expect(timeouts.Wrap(ctx, driverErr)).To(BeIdenticalTo(driverErr))
+	})
+})
+
 func isRoot() bool {
 	cmd := exec.Command("whoami")
 	sess, err := gexec.Start(cmd, GinkgoWriter, GinkgoWriter)
@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/lib/pq/pqerror"
+)
+
+const (
+	mysqlErrLockDeadlock = 1213
+
+	defaultTransactionMaxRetries       = 5
+	defaultTransactionRetryInterval    = 10 * time.Millisecond
+	defaultTransactionMaxRetryInterval = 1 * time.Second
+)
+
+// RetriableTransactor runs transactions, retrying those that fail with a
+// retriable error up to MaxRetries attempts. The delay before each retry
+// doubles from RetryInterval up to MaxRetryInterval, and a random part of up
+// to half of it is taken off so that transactions that conflicted with each
+// other do not retry in lockstep. Sleeper and Int64N are used as in
+// RetriableConnector and DecorrelatedJitterBackoff.
+type RetriableTransactor struct {
+	Beginner         func(context.Context, *sql.TxOptions) (Transaction, error)
+	Monitor          monitor.Monitor
+	Sleeper          sleeper
+	RetryInterval    time.Duration
+	MaxRetryInterval time.Duration
+	MaxRetries       int
+	Int64N           func(n int64) int64
+}
+
+// WithTransaction runs f inside a transaction on conn, committing if f
+// returns nil and rolling back otherwise. Transactions that fail with a
+// serialization failure or deadlock are retried with jittered exponential
+// backoff.
+func WithTransaction(ctx context.Context, conn *ConnWrapper, opts *sql.TxOptions, f func(Transaction) error) error {
+	return newRetriableTransactor(conn).WithTransaction(ctx, opts, f)
+}
+
+func newRetriableTransactor(conn *ConnWrapper) *RetriableTransactor {
+	return &RetriableTransactor{
+		Beginner:         conn.BeginTxx,
+		Monitor:          conn.Monitor,
+		Sleeper:          TimerSleeper{},
+		RetryInterval:    defaultTransactionRetryInterval,
+		MaxRetryInterval: defaultTransactionMaxRetryInterval,
+		MaxRetries:       defaultTransactionMaxRetries,
+	}
+}
+
+func (r *RetriableTransactor) WithTransaction(ctx context.Context, opts *sql.TxOptions, f func(Transaction) error) error {
+	var attempts int
+	for {
+		attempts++
+		err := r.runOnce(ctx, opts, f)
+		if err == nil {
+			return nil
+		}
+
+		if !IsRetriableTransactionError(err) || attempts >= r.MaxRetries {
+			return err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		r.Monitor.RecordRetry()
+		if sleepErr := sleepContext(ctx, r.Sleeper, r.backoff(attempts)); sleepErr != nil {
+			return fmt.Errorf("stopped retrying after %d attempts: %w: last error: %s", attempts, sleepErr, err)
+		}
+	}
+}
+
+func (r *RetriableTransactor) runOnce(ctx context.Context, opts *sql.TxOptions, f func(Transaction) error) error {
+	tx, err := r.Beginner(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			// #nosec G104 - ignore rollback errors, prefer re-raising the original panic
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	if err = f(tx); err != nil {
+		// #nosec G104 - ignore rollback errors, prefer the error returned by the transaction
+		tx.Rollback()
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (r *RetriableTransactor) backoff(attempts int) time.Duration {
+	interval := ExponentialBackoff{Initial: r.RetryInterval, Max: r.MaxRetryInterval}.NextDelay(attempts, 0)
+
+	half := interval / 2
+	if half <= 0 {
+		return interval
+	}
+	return interval - half + time.Duration(randInt64N(r.Int64N)(int64(half)+1))
+}
+
+var (
+	retriableTransactionErrorsLock sync.RWMutex
+	retriableTransactionErrors     []func(error) bool
+)
+
+// RegisterRetriableTransactionError adds isRetriable to the checks made by
+// IsRetriableTransactionError, for drivers registered outside this package
+// such as the one in db/sqlite.
+func RegisterRetriableTransactionError(isRetriable func(error) bool) {
+	retriableTransactionErrorsLock.Lock()
+	defer retriableTransactionErrorsLock.Unlock()
+	retriableTransactionErrors = append(retriableTransactionErrors, isRetriable)
+}
+
+// IsRetriableTransactionError reports whether err is a serialization failure
+// or deadlock reported by the postgres or mysql driver, or an error that a
+// driver registered with RegisterRetriableTransactionError reports as
+// retriable, in which case the whole transaction can safely be retried.
+func IsRetriableTransactionError(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == pqerror.TRSerializationFailure || pqErr.Code == pqerror.TRDeadlockDetected
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == mysqlErrLockDeadlock
+	}
+
+	retriableTransactionErrorsLock.RLock()
+	defer retriableTransactionErrorsLock.RUnlock()
+	for _, isRetriable := range retriableTransactionErrors {
+		if isRetriable(err) {
+			return true
+		}
+	}
+	return false
+}
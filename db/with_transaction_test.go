@@ -0,0 +1,214 @@
+package db_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db"
+	"code.cloudfoundry.org/cf-networking-helpers/db/fakes"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor/monitorfakes"
+	helpersfakes "code.cloudfoundry.org/cf-networking-helpers/fakes"
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetriableTransactor", func() {
+	var (
+		tx          *fakes.Transaction
+		fakeMonitor *monitorfakes.FakeMonitor
+		sleeper     *helpersfakes.Sleeper
+		transactor  *db.RetriableTransactor
+		beginCalls  int
+		passedOpts  *sql.TxOptions
+		opts        *sql.TxOptions
+		deadlock    error
+	)
+
+	BeforeEach(func() {
+		tx = &fakes.Transaction{}
+		fakeMonitor = &monitorfakes.FakeMonitor{}
+		sleeper = &helpersfakes.Sleeper{}
+		beginCalls = 0
+		opts = &sql.TxOptions{Isolation: sql.LevelSerializable}
+		deadlock = &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+		transactor = &db.RetriableTransactor{
+			Beginner: func(ctx context.Context, o *sql.TxOptions) (db.Transaction, error) {
+				beginCalls++
+				passedOpts = o
+				return tx, nil
+			},
+			Monitor:          fakeMonitor,
+			Sleeper:          sleeper,
+			RetryInterval:    10 * time.Millisecond,
+			MaxRetryInterval: 25 * time.Millisecond,
+			MaxRetries:       4,
+			Int64N:           func(n int64) int64 { return n - 1 },
+		}
+	})
+
+	It("commits the transaction when the function succeeds", func() {
+		err := transactor.WithTransaction(context.Background(), opts, func(t db.Transaction) error {
+			Expect(t).To(Equal(tx))
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(passedOpts).To(Equal(opts))
+		Expect(tx.CommitCallCount()).To(Equal(1))
+		Expect(tx.RollbackCallCount()).To(Equal(0))
+		Expect(fakeMonitor.RecordRetryCallCount()).To(Equal(0))
+	})
+
+	Context("when the function returns a non-retriable error", func() {
+		It("rolls back and returns the error without retrying", func() {
+			err := transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+				return errors.New("banana")
+			})
+			Expect(err).To(MatchError("banana"))
+
+			Expect(beginCalls).To(Equal(1))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+			Expect(tx.CommitCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when beginning the transaction fails", func() {
+		It("returns the error", func() {
+			transactor.Beginner = func(context.Context, *sql.TxOptions) (db.Transaction, error) {
+				return nil, errors.New("no connection")
+			}
+
+			err := transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+				Fail("should not be called")
+				return nil
+			})
+			Expect(err).To(MatchError("begin transaction: no connection"))
+		})
+	})
+
+	Context("when the function returns a retriable error", func() {
+		It("rolls back, backs off and retries until it succeeds", func() {
+			calls := 0
+			err := transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+				calls++
+				if calls < 4 {
+					return fmt.Errorf("updating: %w", deadlock)
+				}
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(beginCalls).To(Equal(4))
+			Expect(tx.RollbackCallCount()).To(Equal(3))
+			Expect(tx.CommitCallCount()).To(Equal(1))
+			Expect(fakeMonitor.RecordRetryCallCount()).To(Equal(3))
+
+			Expect(sleeper.SleepCallCount()).To(Equal(3))
+			Expect(sleeper.SleepArgsForCall(0)).To(Equal(10 * time.Millisecond))
+			Expect(sleeper.SleepArgsForCall(1)).To(Equal(20 * time.Millisecond))
+			Expect(sleeper.SleepArgsForCall(2)).To(Equal(25 * time.Millisecond))
+		})
+
+		It("takes a random part of up to half of each delay off", func() {
+			transactor.Int64N = func(n int64) int64 { return 0 }
+
+			calls := 0
+			err := transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+				calls++
+				if calls < 3 {
+					return deadlock
+				}
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(sleeper.SleepCallCount()).To(Equal(2))
+			Expect(sleeper.SleepArgsForCall(0)).To(Equal(5 * time.Millisecond))
+			Expect(sleeper.SleepArgsForCall(1)).To(Equal(10 * time.Millisecond))
+		})
+
+		It("stops after max retries and returns the last error", func() {
+			err := transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+				return deadlock
+			})
+			Expect(err).To(MatchError(deadlock))
+
+			Expect(beginCalls).To(Equal(4))
+			Expect(fakeMonitor.RecordRetryCallCount()).To(Equal(3))
+		})
+
+		It("does not retry once the context is done", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			err := transactor.WithTransaction(ctx, opts, func(db.Transaction) error {
+				return deadlock
+			})
+			Expect(err).To(MatchError(deadlock))
+			Expect(beginCalls).To(Equal(1))
+		})
+
+		It("stops waiting to retry once the context is done", func() {
+			transactor.Sleeper = db.TimerSleeper{}
+			transactor.RetryInterval = time.Minute
+			transactor.MaxRetryInterval = time.Minute
+
+			ctx, cancel := context.WithCancel(context.Background())
+			time.AfterFunc(10*time.Millisecond, cancel)
+
+			err := transactor.WithTransaction(ctx, opts, func(db.Transaction) error {
+				return deadlock
+			})
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(err).To(MatchError(ContainSubstring("last error: Error 1213")))
+			Expect(beginCalls).To(Equal(1))
+		})
+	})
+
+	Context("when the commit fails with a retriable error", func() {
+		It("retries the transaction", func() {
+			tx.CommitReturnsOnCall(0, &pq.Error{Code: "40001"})
+
+			err := transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+				return nil
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(beginCalls).To(Equal(2))
+			Expect(tx.CommitCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the function panics", func() {
+		It("rolls back and re-panics", func() {
+			Expect(func() {
+				transactor.WithTransaction(context.Background(), opts, func(db.Transaction) error {
+					panic("boom")
+				})
+			}).To(PanicWith("boom"))
+			Expect(tx.RollbackCallCount()).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("IsRetriableTransactionError", func() {
+	DescribeTable("classifies driver errors",
+		func(err error, expected bool) {
+			Expect(db.IsRetriableTransactionError(err)).To(Equal(expected))
+		},
+		Entry("postgres serialization failure", &pq.Error{Code: "40001"}, true),
+		Entry("postgres deadlock", &pq.Error{Code: "40P01"}, true),
+		Entry("wrapped postgres deadlock", fmt.Errorf("commit: %w", &pq.Error{Code: "40P01"}), true),
+		Entry("postgres unique violation", &pq.Error{Code: "23505"}, false),
+		Entry("mysql deadlock", &mysql.MySQLError{Number: 1213}, true),
+		Entry("mysql duplicate entry", &mysql.MySQLError{Number: 1062}, false),
+		Entry("generic error", errors.New("banana"), false),
+		Entry("nil", nil, false),
+	)
+})
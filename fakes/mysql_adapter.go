@@ -9,6 +9,11 @@ import (
 )
 
 type MySQLAdapter struct {
+	DeregisterTLSConfigStub        func(string)
+	deregisterTLSConfigMutex       sync.RWMutex
+	deregisterTLSConfigArgsForCall []struct {
+		arg1 string
+	}
 	ParseDSNStub        func(string) (*mysql.Config, error)
 	parseDSNMutex       sync.RWMutex
 	parseDSNArgsForCall []struct {
@@ -38,6 +43,38 @@ type MySQLAdapter struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *MySQLAdapter) DeregisterTLSConfig(arg1 string) {
+	fake.deregisterTLSConfigMutex.Lock()
+	fake.deregisterTLSConfigArgsForCall = append(fake.deregisterTLSConfigArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.DeregisterTLSConfigStub
+	fake.recordInvocation("DeregisterTLSConfig", []interface{}{arg1})
+	fake.deregisterTLSConfigMutex.Unlock()
+	if stub != nil {
+		fake.DeregisterTLSConfigStub(arg1)
+	}
+}
+
+func (fake *MySQLAdapter) DeregisterTLSConfigCallCount() int {
+	fake.deregisterTLSConfigMutex.RLock()
+	defer fake.deregisterTLSConfigMutex.RUnlock()
+	return len(fake.deregisterTLSConfigArgsForCall)
+}
+
+func (fake *MySQLAdapter) DeregisterTLSConfigCalls(stub func(string)) {
+	fake.deregisterTLSConfigMutex.Lock()
+	defer fake.deregisterTLSConfigMutex.Unlock()
+	fake.DeregisterTLSConfigStub = stub
+}
+
+func (fake *MySQLAdapter) DeregisterTLSConfigArgsForCall(i int) string {
+	fake.deregisterTLSConfigMutex.RLock()
+	defer fake.deregisterTLSConfigMutex.RUnlock()
+	argsForCall := fake.deregisterTLSConfigArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *MySQLAdapter) ParseDSN(arg1 string) (*mysql.Config, error) {
 	fake.parseDSNMutex.Lock()
 	ret, specificReturn := fake.parseDSNReturnsOnCall[len(fake.parseDSNArgsForCall)]
@@ -167,6 +204,8 @@ func (fake *MySQLAdapter) RegisterTLSConfigReturnsOnCall(i int, result1 error) {
 func (fake *MySQLAdapter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.deregisterTLSConfigMutex.RLock()
+	defer fake.deregisterTLSConfigMutex.RUnlock()
 	fake.parseDSNMutex.RLock()
 	defer fake.parseDSNMutex.RUnlock()
 	fake.registerTLSConfigMutex.RLock()
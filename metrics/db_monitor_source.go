@@ -1,13 +1,40 @@
 package metrics
 
-import "code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+import (
+	"database/sql"
+	"sort"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+)
 
 type Db interface {
 	OpenConnections() int
 }
 
-func NewDBMonitorSource(Db Db, monitor monitor.Monitor) []MetricSource {
-	return []MetricSource{
+// DbStats is implemented by a Db that can report full connection pool
+// statistics, such as *db.ConnWrapper. When the Db passed to
+// NewDBMonitorSource implements it, the pool sources are reported as well.
+type DbStats interface {
+	Stats() sql.DBStats
+}
+
+func NewDBMonitorSource(Db Db, dbMonitor monitor.Monitor) []MetricSource {
+	return NewDBMonitorSourceWithPrefix("", Db, dbMonitor)
+}
+
+// NewDBMonitorSourceWithPrefix returns the same sources as NewDBMonitorSource
+// with prefix prepended to each name, so that several connection pools, such
+// as a primary and its read replicas, can be reported side by side.
+func NewDBMonitorSourceWithPrefix(prefix string, Db Db, dbMonitor monitor.Monitor) []MetricSource {
+	sources := newDBMonitorSource(Db, dbMonitor)
+	for i := range sources {
+		sources[i].Name = prefix + sources[i].Name
+	}
+	return sources
+}
+
+func newDBMonitorSource(Db Db, dbMonitor monitor.Monitor) []MetricSource {
+	sources := []MetricSource{
 		{
 			Name: "DBOpenConnections",
 			Unit: "",
@@ -19,35 +46,195 @@ func NewDBMonitorSource(Db Db, monitor monitor.Monitor) []MetricSource {
 			Name: "DBQueriesTotal",
 			Unit: "",
 			Getter: func() (float64, error) {
-				return float64(monitor.Total()), nil
+				return float64(dbMonitor.Total()), nil
 			},
 		},
 		{
 			Name: "DBQueriesSucceeded",
 			Unit: "",
 			Getter: func() (float64, error) {
-				return float64(monitor.Succeeded()), nil
+				return float64(dbMonitor.Succeeded()), nil
 			},
 		},
 		{
 			Name: "DBQueriesFailed",
 			Unit: "",
 			Getter: func() (float64, error) {
-				return float64(monitor.Failed()), nil
+				return float64(dbMonitor.Failed()), nil
+			},
+		},
+		{
+			Name: "DBQueriesTimedOut",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(dbMonitor.TimedOut()), nil
+			},
+		},
+		{
+			Name: "DBTransactionsRetried",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(dbMonitor.Retries()), nil
+			},
+		},
+		{
+			Name: "DBConnectRetries",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(dbMonitor.ConnectRetries()), nil
+			},
+		},
+		{
+			Name: "DBSlowQueries",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(dbMonitor.SlowQueries()), nil
 			},
 		},
 		{
 			Name: "DBQueriesInFlight",
 			Unit: "",
 			Getter: func() (float64, error) {
-				return float64(monitor.ReadAndResetInFlightMax()), nil
+				return float64(dbMonitor.ReadAndResetInFlightMax()), nil
 			},
 		},
 		{
 			Name: "DBQueryDurationMax",
 			Unit: "seconds",
 			Getter: func() (float64, error) {
-				return monitor.ReadAndResetDurationMax().Seconds(), nil
+				return dbMonitor.ReadAndResetDurationMax().Seconds(), nil
+			},
+		},
+		{
+			// the percentiles are read from one histogram per interval
+			Name: "DBQueryDuration",
+			SnapshotGetter: func() ([]Metric, error) {
+				durations := dbMonitor.ReadAndResetDurationHistogram()
+				return []Metric{
+					{Name: "P50", Value: durations.Percentile(50).Seconds(), Unit: "seconds"},
+					{Name: "P90", Value: durations.Percentile(90).Seconds(), Unit: "seconds"},
+					{Name: "P99", Value: durations.Percentile(99).Seconds(), Unit: "seconds"},
+				}, nil
+			},
+		},
+		{
+			// the per query name stats are read from one snapshot per
+			// interval, and emitted as e.g. DBNamedQueriesTotal.<name>
+			Name: "DBNamed",
+			SnapshotGetter: func() ([]Metric, error) {
+				return namedQueryMetrics(dbMonitor.ReadAndResetQueryStats()), nil
+			},
+		},
+	}
+
+	if dbStats, ok := Db.(DbStats); ok {
+		sources = append(sources, newDBPoolSource(dbStats)...)
+	}
+	return sources
+}
+
+func namedQueryMetrics(queryStats map[string]monitor.QueryStats) []Metric {
+	names := make([]string, 0, len(queryStats))
+	for name := range queryStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metrics := make([]Metric, 0, 5*len(names))
+	for _, name := range names {
+		stats := queryStats[name]
+		metrics = append(metrics,
+			Metric{Name: "QueriesTotal." + name, Value: float64(stats.Total), Unit: ""},
+			Metric{Name: "QueriesSucceeded." + name, Value: float64(stats.Succeeded), Unit: ""},
+			Metric{Name: "QueriesFailed." + name, Value: float64(stats.Failed), Unit: ""},
+			Metric{Name: "QueriesTimedOut." + name, Value: float64(stats.TimedOut), Unit: ""},
+			Metric{Name: "QueryDurationMax." + name, Value: stats.DurationMax.Seconds(), Unit: "seconds"},
+		)
+	}
+	return metrics
+}
+
+// newDBPoolSource reports the number of connections in use and idle, and how
+// much the cumulative sql.DBStats counters grew during each emit interval,
+// all read from one stats snapshot per interval.
+func newDBPoolSource(dbStats DbStats) []MetricSource {
+	var previous sql.DBStats
+	return []MetricSource{
+		{
+			Name: "DBConnections",
+			SnapshotGetter: func() ([]Metric, error) {
+				current := dbStats.Stats()
+				defer func() { previous = current }()
+				return []Metric{
+					{Name: "InUse", Value: float64(current.InUse), Unit: ""},
+					{Name: "Idle", Value: float64(current.Idle), Unit: ""},
+					{Name: "WaitCount", Value: float64(current.WaitCount - previous.WaitCount), Unit: ""},
+					{Name: "WaitDuration", Value: (current.WaitDuration - previous.WaitDuration).Seconds(), Unit: "seconds"},
+					{Name: "MaxIdleClosed", Value: float64(current.MaxIdleClosed - previous.MaxIdleClosed), Unit: ""},
+					{Name: "MaxLifetimeClosed", Value: float64(current.MaxLifetimeClosed - previous.MaxLifetimeClosed), Unit: ""},
+					{Name: "MaxIdleTimeClosed", Value: float64(current.MaxIdleTimeClosed - previous.MaxIdleTimeClosed), Unit: ""},
+				}, nil
+			},
+		},
+	}
+}
+
+type DbHealth interface {
+	Healthy() bool
+}
+
+// NewDBHealthSource reports 1 while the database is healthy and 0 otherwise.
+func NewDBHealthSource(health DbHealth) MetricSource {
+	return MetricSource{
+		Name: "DBHealthy",
+		Unit: "",
+		Getter: func() (float64, error) {
+			if health.Healthy() {
+				return 1, nil
+			}
+			return 0, nil
+		},
+	}
+}
+
+// StmtCache is implemented by *db.StmtCache.
+type StmtCache interface {
+	Len() int
+	Hits() int64
+	Misses() int64
+	Evictions() int64
+}
+
+// NewStmtCacheSource reports the number of statements in the cache and the
+// cumulative hits, misses and evictions.
+func NewStmtCacheSource(cache StmtCache) []MetricSource {
+	return []MetricSource{
+		{
+			Name: "DBStmtCacheSize",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(cache.Len()), nil
+			},
+		},
+		{
+			Name: "DBStmtCacheHits",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(cache.Hits()), nil
+			},
+		},
+		{
+			Name: "DBStmtCacheMisses",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(cache.Misses()), nil
+			},
+		},
+		{
+			Name: "DBStmtCacheEvictions",
+			Unit: "",
+			Getter: func() (float64, error) {
+				return float64(cache.Evictions()), nil
 			},
 		},
 	}
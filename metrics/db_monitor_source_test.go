@@ -0,0 +1,259 @@
+package metrics_test
+
+import (
+	"database/sql"
+	"time"
+
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor"
+	"code.cloudfoundry.org/cf-networking-helpers/db/monitor/monitorfakes"
+	"code.cloudfoundry.org/cf-networking-helpers/metrics"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDb struct {
+	openConnections int
+}
+
+func (f *fakeDb) OpenConnections() int {
+	return f.openConnections
+}
+
+var _ = Describe("DBMonitorSource", func() {
+	var (
+		fakeMonitor *monitorfakes.FakeMonitor
+		sources     map[string]metrics.MetricSource
+		names       []string
+	)
+
+	BeforeEach(func() {
+		fakeMonitor = &monitorfakes.FakeMonitor{}
+		sources = map[string]metrics.MetricSource{}
+		names = nil
+		for _, source := range metrics.NewDBMonitorSource(&fakeDb{openConnections: 7}, fakeMonitor) {
+			sources[source.Name] = source
+			names = append(names, source.Name)
+		}
+	})
+
+	value := func(name string) float64 {
+		source, ok := sources[name]
+		Expect(ok).To(BeTrue(), "missing source "+name)
+		v, err := source.Getter()
+		Expect(err).NotTo(HaveOccurred())
+		return v
+	}
+
+	snapshot := func(name string) map[string]float64 {
+		source, ok := sources[name]
+		Expect(ok).To(BeTrue(), "missing source "+name)
+		metrics, err := source.SnapshotGetter()
+		Expect(err).NotTo(HaveOccurred())
+		values := map[string]float64{}
+		for _, metric := range metrics {
+			values[name+metric.Name] = metric.Value
+		}
+		return values
+	}
+
+	It("reports open connections and query counts", func() {
+		fakeMonitor.TotalReturns(10)
+		fakeMonitor.SucceededReturns(8)
+		fakeMonitor.FailedReturns(2)
+		fakeMonitor.TimedOutReturns(5)
+		fakeMonitor.RetriesReturns(3)
+		fakeMonitor.ConnectRetriesReturns(6)
+		fakeMonitor.SlowQueriesReturns(4)
+
+		Expect(value("DBOpenConnections")).To(Equal(7.0))
+		Expect(value("DBQueriesTotal")).To(Equal(10.0))
+		Expect(value("DBQueriesSucceeded")).To(Equal(8.0))
+		Expect(value("DBQueriesFailed")).To(Equal(2.0))
+		Expect(value("DBQueriesTimedOut")).To(Equal(5.0))
+		Expect(value("DBTransactionsRetried")).To(Equal(3.0))
+		Expect(value("DBConnectRetries")).To(Equal(6.0))
+		Expect(value("DBSlowQueries")).To(Equal(4.0))
+	})
+
+	It("reports duration percentiles from a single histogram snapshot per interval", func() {
+		var histogram monitor.DurationHistogram
+		for i := 1; i <= 100; i++ {
+			histogram.Record(time.Duration(i) * time.Millisecond)
+		}
+		fakeMonitor.ReadAndResetDurationHistogramReturnsOnCall(0, histogram)
+
+		durations := snapshot("DBQueryDuration")
+		Expect(durations).To(HaveLen(3))
+		Expect(durations["DBQueryDurationP50"]).To(BeNumerically("~", 0.050, 0.010))
+		Expect(durations["DBQueryDurationP90"]).To(BeNumerically("~", 0.090, 0.018))
+		Expect(durations["DBQueryDurationP99"]).To(BeNumerically("~", 0.099, 0.002))
+		Expect(fakeMonitor.ReadAndResetDurationHistogramCallCount()).To(Equal(1))
+
+		Expect(snapshot("DBQueryDuration")).To(HaveEach(BeZero()))
+		Expect(fakeMonitor.ReadAndResetDurationHistogramCallCount()).To(Equal(2))
+	})
+
+	It("reports per query name stats from a single snapshot per interval", func() {
+		fakeMonitor.ReadAndResetQueryStatsReturnsOnCall(0, map[string]monitor.QueryStats{
+			"select-policies": {Total: 6, Succeeded: 4, Failed: 1, TimedOut: 1, DurationMax: 2 * time.Second},
+			"delete-policies": {Total: 1, Succeeded: 1},
+		})
+
+		Expect(snapshot("DBNamed")).To(Equal(map[string]float64{
+			"DBNamedQueriesTotal.select-policies":     6,
+			"DBNamedQueriesSucceeded.select-policies": 4,
+			"DBNamedQueriesFailed.select-policies":    1,
+			"DBNamedQueriesTimedOut.select-policies":  1,
+			"DBNamedQueryDurationMax.select-policies": 2,
+			"DBNamedQueriesTotal.delete-policies":     1,
+			"DBNamedQueriesSucceeded.delete-policies": 1,
+			"DBNamedQueriesFailed.delete-policies":    0,
+			"DBNamedQueriesTimedOut.delete-policies":  0,
+			"DBNamedQueryDurationMax.delete-policies": 0,
+		}))
+		Expect(fakeMonitor.ReadAndResetQueryStatsCallCount()).To(Equal(1))
+
+		Expect(snapshot("DBNamed")).To(BeEmpty())
+		Expect(fakeMonitor.ReadAndResetQueryStatsCallCount()).To(Equal(2))
+	})
+
+	Describe("NewDBMonitorSourceWithPrefix", func() {
+		It("prefixes each source name", func() {
+			prefixed := metrics.NewDBMonitorSourceWithPrefix("Replica0", &fakeDb{}, fakeMonitor)
+			Expect(prefixed).To(HaveLen(len(names)))
+			for i, source := range prefixed {
+				Expect(source.Name).To(Equal("Replica0" + names[i]))
+			}
+		})
+	})
+})
+
+type fakeStatsDb struct {
+	fakeDb
+	stats      sql.DBStats
+	statsCalls int
+}
+
+func (f *fakeStatsDb) Stats() sql.DBStats {
+	f.statsCalls++
+	return f.stats
+}
+
+var _ = Describe("DBMonitorSource pool stats", func() {
+	var (
+		statsDb *fakeStatsDb
+		sources []metrics.MetricSource
+	)
+
+	BeforeEach(func() {
+		statsDb = &fakeStatsDb{}
+		sources = metrics.NewDBMonitorSource(statsDb, &monitorfakes.FakeMonitor{})
+	})
+
+	emit := func() map[string]float64 {
+		values := map[string]float64{}
+		for _, source := range sources {
+			if source.SnapshotGetter == nil {
+				continue
+			}
+			metrics, err := source.SnapshotGetter()
+			Expect(err).NotTo(HaveOccurred())
+			for _, metric := range metrics {
+				values[source.Name+metric.Name] = metric.Value
+			}
+		}
+		return values
+	}
+
+	It("reports in use and idle connections and the growth of the counters per interval", func() {
+		statsDb.stats = sql.DBStats{
+			InUse:             3,
+			Idle:              2,
+			WaitCount:         5,
+			WaitDuration:      2 * time.Second,
+			MaxIdleClosed:     1,
+			MaxLifetimeClosed: 4,
+			MaxIdleTimeClosed: 6,
+		}
+		Expect(emit()).To(SatisfyAll(
+			HaveKeyWithValue("DBConnectionsInUse", 3.0),
+			HaveKeyWithValue("DBConnectionsIdle", 2.0),
+			HaveKeyWithValue("DBConnectionsWaitCount", 5.0),
+			HaveKeyWithValue("DBConnectionsWaitDuration", 2.0),
+			HaveKeyWithValue("DBConnectionsMaxIdleClosed", 1.0),
+			HaveKeyWithValue("DBConnectionsMaxLifetimeClosed", 4.0),
+			HaveKeyWithValue("DBConnectionsMaxIdleTimeClosed", 6.0),
+		))
+		Expect(statsDb.statsCalls).To(Equal(1))
+
+		statsDb.stats.InUse = 1
+		statsDb.stats.WaitCount = 8
+		statsDb.stats.WaitDuration = 2500 * time.Millisecond
+		Expect(emit()).To(SatisfyAll(
+			HaveKeyWithValue("DBConnectionsInUse", 1.0),
+			HaveKeyWithValue("DBConnectionsWaitCount", 3.0),
+			HaveKeyWithValue("DBConnectionsWaitDuration", 0.5),
+			HaveKeyWithValue("DBConnectionsMaxIdleClosed", 0.0),
+			HaveKeyWithValue("DBConnectionsMaxLifetimeClosed", 0.0),
+		))
+	})
+
+	It("does not report pool stats for a Db that cannot provide them", func() {
+		for _, source := range metrics.NewDBMonitorSource(&fakeDb{}, &monitorfakes.FakeMonitor{}) {
+			Expect(source.Name).NotTo(HavePrefix("DBConnections"))
+		}
+	})
+})
+
+type fakeDbHealth struct {
+	healthy bool
+}
+
+func (f *fakeDbHealth) Healthy() bool {
+	return f.healthy
+}
+
+var _ = Describe("DBHealthSource", func() {
+	It("reports 1 while the database is healthy and 0 otherwise", func() {
+		health := &fakeDbHealth{healthy: true}
+		source := metrics.NewDBHealthSource(health)
+		Expect(source.Name).To(Equal("DBHealthy"))
+
+		v, err := source.Getter()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(1.0))
+
+		health.healthy = false
+		v, err = source.Getter()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(v).To(Equal(0.0))
+	})
+})
+
+type fakeStmtCache struct {
+	len                     int
+	hits, misses, evictions int64
+}
+
+func (f *fakeStmtCache) Len() int         { return f.len }
+func (f *fakeStmtCache) Hits() int64      { return f.hits }
+func (f *fakeStmtCache) Misses() int64    { return f.misses }
+func (f *fakeStmtCache) Evictions() int64 { return f.evictions }
+
+var _ = Describe("StmtCacheSource", func() {
+	It("reports the cache size and counts", func() {
+		values := map[string]float64{}
+		for _, source := range metrics.NewStmtCacheSource(&fakeStmtCache{len: 4, hits: 10, misses: 6, evictions: 2}) {
+			v, err := source.Getter()
+			Expect(err).NotTo(HaveOccurred())
+			values[source.Name] = v
+		}
+
+		Expect(values).To(Equal(map[string]float64{
+			"DBStmtCacheSize":      4,
+			"DBStmtCacheHits":      10,
+			"DBStmtCacheMisses":    6,
+			"DBStmtCacheEvictions": 2,
+		}))
+	})
+})
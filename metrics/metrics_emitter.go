@@ -13,6 +13,19 @@ type MetricSource struct {
 	Name   string
 	Unit   string
 	Getter func() (float64, error)
+
+	// SnapshotGetter, when set, is used instead of Getter for a group of
+	// values that must be read together, such as from one snapshot of stats
+	// that is reset when read. Each value is emitted as "<Name><Metric.Name>"
+	// with its own unit.
+	SnapshotGetter func() ([]Metric, error)
+}
+
+// Metric is one of the values returned by a SnapshotGetter.
+type Metric struct {
+	Name  string
+	Value float64
+	Unit  string
 }
 
 type MetricsEmitter struct {
@@ -45,6 +58,11 @@ func (m *MetricsEmitter) Run(signals <-chan os.Signal, ready chan<- struct{}) er
 
 func (m *MetricsEmitter) emitMetrics() {
 	for _, source := range m.metrics {
+		if source.SnapshotGetter != nil {
+			m.emitSnapshot(source)
+			continue
+		}
+
 		value, err := source.Getter()
 		if err != nil {
 			m.logger.Error("metric-getter", err, lager.Data{"source": source.Name})
@@ -58,6 +76,22 @@ func (m *MetricsEmitter) emitMetrics() {
 	}
 }
 
+func (m *MetricsEmitter) emitSnapshot(source MetricSource) {
+	values, err := source.SnapshotGetter()
+	if err != nil {
+		m.logger.Error("metric-getter", err, lager.Data{"source": source.Name})
+		return
+	}
+
+	for _, value := range values {
+		name := source.Name + value.Name
+		err = dropsondemetrics.SendValue(name, value.Value, value.Unit)
+		if err != nil {
+			m.logger.Error("metric-send", err, lager.Data{"source": name})
+		}
+	}
+}
+
 func (m *MetricsEmitter) EmitMetrics() {
 	m.emitMetrics()
 }
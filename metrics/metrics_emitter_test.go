@@ -88,6 +88,47 @@ var _ = Describe("MetricsEmitter", func() {
 		Expect(*metric.Value).To(Equal(42.0))
 	})
 
+	Context("when the metric source has a snapshot getter", func() {
+		It("reports each value under the source name followed by its own name and unit", func() {
+			snapshotSource := metrics.MetricSource{
+				Name: "snapshotSource",
+				SnapshotGetter: func() ([]metrics.Metric, error) {
+					return []metrics.Metric{
+						{Name: "Count", Value: 3, Unit: ""},
+						{Name: "Duration", Value: 0.5, Unit: "seconds"},
+					}, nil
+				},
+			}
+			metricsEmitter = metrics.NewMetricsEmitter(logger, interval, snapshotSource)
+			metricsEmitterProc = ifrit.Invoke(metricsEmitter)
+			Eventually(metricsEmitterProc.Ready()).Should(BeClosed())
+			Expect(fakeDropsonde.GetMessages()).To(HaveLen(2))
+
+			metric := fakeDropsonde.GetMessages()[0].Event.(*events.ValueMetric)
+			Expect(metric.Name).To(Equal(proto.String("snapshotSourceCount")))
+			Expect(metric.Unit).To(Equal(proto.String("")))
+			Expect(*metric.Value).To(Equal(3.0))
+
+			metric = fakeDropsonde.GetMessages()[1].Event.(*events.ValueMetric)
+			Expect(metric.Name).To(Equal(proto.String("snapshotSourceDuration")))
+			Expect(metric.Unit).To(Equal(proto.String("seconds")))
+			Expect(*metric.Value).To(Equal(0.5))
+		})
+
+		It("logs the error when the snapshot getter fails", func() {
+			badSource := metrics.MetricSource{
+				Name: "badSnapshotSource",
+				SnapshotGetter: func() ([]metrics.Metric, error) {
+					return nil, errors.New("potato")
+				},
+			}
+			metricsEmitter = metrics.NewMetricsEmitter(logger, interval, badSource)
+			metricsEmitterProc = ifrit.Invoke(metricsEmitter)
+			Eventually(logger).Should(gbytes.Say("metric-getter.*potato.*badSnapshotSource"))
+			Consistently(fakeDropsonde.GetMessages, "300ms").Should(BeEmpty())
+		})
+	})
+
 	Context("when the metric source getter fails", func() {
 		BeforeEach(func() {
 			badSource := metrics.MetricSource{